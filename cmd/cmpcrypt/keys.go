@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hsjahng/cmp-common/crypto"
+	"golang.org/x/term"
+)
+
+// 키 읽기
+//
+// 키는 keygen 이 출력하는 base64 인코딩 32바이트 값이며 아래 순서로 찾는다.
+//
+//  1. -prompt: 터미널에서 입력 (입력 내용은 화면에 표시하지 않음)
+//  2. -key-file: 파일 내용 (앞뒤 공백 제거)
+//  3. -key-env: 환경 변수 (기본 CMPCRYPT_KEY)
+//
+// 키를 명령행 인자로 직접 받지 않는 것은 셸 히스토리와 프로세스 목록에 남지 않도록 하기 위함이다.
+
+const (
+	// DefaultKeyEnv 키를 읽는 기본 환경 변수
+	DefaultKeyEnv = "CMPCRYPT_KEY"
+	// DefaultNewKeyEnv rotate 에서 새 키를 읽는 기본 환경 변수
+	DefaultNewKeyEnv = "CMPCRYPT_NEW_KEY"
+)
+
+// keySource 키를 읽을 위치
+type keySource struct {
+	name   string // 오류 메시지와 프롬프트에 쓰는 이름
+	keyID  string
+	env    string
+	file   string
+	prompt bool
+}
+
+// register 플래그 등록, prefix 는 rotate 의 새 키처럼 같은 명령에서 키를 두 개 받을 때 사용
+func (s *keySource) register(fs *flag.FlagSet, prefix, defaultEnv string) {
+	fs.StringVar(&s.keyID, prefix+"key-id", "", "봉투에 기록할 키 ID")
+	fs.StringVar(&s.env, prefix+"key-env", defaultEnv, "키를 읽을 환경 변수")
+	fs.StringVar(&s.file, prefix+"key-file", "", "키를 읽을 파일")
+	fs.BoolVar(&s.prompt, prefix+"prompt", false, "터미널에서 키 입력")
+}
+
+// load 키 읽기, 다 쓰면 Destroy
+func (s *keySource) load(env *environment) (*crypto.SecretBytes, error) {
+	var encoded []byte
+	switch {
+	case s.prompt:
+		value, err := env.readSecret(fmt.Sprintf("%s 키 입력: ", s.name))
+		if err != nil {
+			return nil, fmt.Errorf("%s 키 입력 실패: %w", s.name, err)
+		}
+		encoded = value
+	case len(s.file) > 0:
+		value, err := os.ReadFile(s.file)
+		if err != nil {
+			return nil, fmt.Errorf("%s 키 파일 읽기 실패: %w", s.name, err)
+		}
+		encoded = value
+	case len(s.env) > 0 && len(env.getenv(s.env)) > 0:
+		encoded = []byte(env.getenv(s.env))
+	default:
+		return nil, fmt.Errorf("%s 키가 없습니다: -key-file, -prompt 또는 %s 환경 변수로 지정하세요", s.name, s.env)
+	}
+	defer clear(encoded)
+
+	return decodeKey(encoded)
+}
+
+// decodeKey base64 키 디코딩
+func decodeKey(encoded []byte) (*crypto.SecretBytes, error) {
+	encoded = []byte(strings.TrimSpace(string(encoded)))
+	key := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(key, encoded)
+	if err != nil {
+		clear(key)
+		return nil, fmt.Errorf("키는 base64 로 인코딩되어 있어야 합니다: %w", err)
+	}
+	secret := crypto.NewSecretBytes(key[:n])
+	if secret.Len() != crypto.KeySize {
+		secret.Destroy()
+		return nil, &crypto.KeySizeError{Want: crypto.KeySize, Got: n}
+	}
+	return secret, nil
+}
+
+// encodeKey keygen 출력 형식
+func encodeKey(key *crypto.SecretBytes) string {
+	return base64.StdEncoding.EncodeToString(key.Bytes())
+}
+
+// environment 명령이 사용하는 입출력과 환경 (테스트에서 바꿔 끼운다)
+type environment struct {
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	getenv   func(string) string
+	terminal io.Reader // -prompt 입력, nil 이면 /dev/tty
+}
+
+func defaultEnvironment() *environment {
+	return &environment{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+}
+
+// readSecret 프롬프트를 stderr 에 출력하고 터미널에서 한 줄 읽기
+//
+// 암호화할 값을 stdin 으로 받는 중에도 키를 입력할 수 있도록 stdin 대신 /dev/tty 를 열고, 입력 내용은 화면에 표시하지 않는다.
+func (e *environment) readSecret(prompt string) ([]byte, error) {
+	fmt.Fprint(e.stderr, prompt)
+	defer fmt.Fprintln(e.stderr)
+
+	if e.terminal != nil {
+		line, err := bufio.NewReader(e.terminal).ReadBytes('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+			return nil, err
+		}
+		return line, nil
+	}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return nil, fmt.Errorf("터미널을 열 수 없습니다 (-key-file 또는 환경 변수를 사용하세요): %w", err)
+	}
+	defer tty.Close()
+	return term.ReadPassword(int(tty.Fd()))
+}
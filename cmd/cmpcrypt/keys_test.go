@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hsjahng/cmp-common/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySource(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	encoded := encodeKey(key)
+
+	t.Run("Env", func(t *testing.T) {
+		env, _, _ := testEnvironment("", map[string]string{"MY_KEY": encoded})
+		source := keySource{name: "테스트", env: "MY_KEY"}
+		loaded, err := source.load(env)
+		require.NoError(t, err)
+		require.Equal(t, key.Bytes(), loaded.Bytes())
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "key.txt")
+		require.NoError(t, os.WriteFile(path, []byte(encoded+"\n"), 0600))
+
+		// 파일이 환경 변수보다 우선
+		env, _, _ := testEnvironment("", map[string]string{DefaultKeyEnv: "ignored"})
+		source := keySource{name: "테스트", env: DefaultKeyEnv, file: path}
+		loaded, err := source.load(env)
+		require.NoError(t, err)
+		require.Equal(t, key.Bytes(), loaded.Bytes())
+	})
+
+	t.Run("Prompt", func(t *testing.T) {
+		env, stdout, stderr := testEnvironment("values on stdin\n", nil)
+		env.terminal = strings.NewReader(encoded + "\n")
+		source := keySource{name: "테스트", prompt: true}
+		loaded, err := source.load(env)
+		require.NoError(t, err)
+		require.Equal(t, key.Bytes(), loaded.Bytes())
+
+		require.Contains(t, stderr.String(), "테스트 키 입력")
+		require.NotContains(t, stderr.String(), encoded)
+		require.Empty(t, stdout.String())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		env, _, _ := testEnvironment("", map[string]string{"SHORT": "c2hvcnQ=", "BAD": "not base64!"})
+
+		_, err := (&keySource{name: "테스트", env: "SHORT"}).load(env)
+		require.ErrorIs(t, err, crypto.ErrInvalidKeySize)
+		_, err = (&keySource{name: "테스트", env: "BAD"}).load(env)
+		require.Error(t, err)
+		_, err = (&keySource{name: "테스트", env: "MISSING"}).load(env)
+		require.Error(t, err)
+		_, err = (&keySource{name: "테스트", file: "/nonexistent/key"}).load(env)
+		require.Error(t, err)
+	})
+}
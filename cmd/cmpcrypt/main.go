@@ -0,0 +1,450 @@
+// cmpcrypt crypto 패키지로 설정 파일 값 등을 암호화, 복호화하는 운영자용 도구
+//
+//	cmpcrypt keygen [-out key.txt]
+//	cmpcrypt encrypt [키 옵션] [-algorithm aes-256-gcm] [-in 파일] [-out 파일]
+//	cmpcrypt decrypt [키 옵션] [-in 파일] [-out 파일]
+//	cmpcrypt rotate  [키 옵션] [새 키 옵션] [-in 파일] [-out 파일]
+//	cmpcrypt inspect [-in 파일] [-out 파일]
+//
+// encrypt, decrypt, rotate, inspect 는 입력(기본 stdin)의 각 줄을 값 하나로 보고 같은 순서로 한 줄씩 출력한다.
+// 빈 줄은 그대로 빈 줄로 출력한다. 줄은 -batch 개씩 묶어 crypto 의 대량 API 로 처리하며,
+// 묶음 안에서 하나라도 실패하면 실패한 줄 번호를 stderr 에 출력하고 그 묶음부터는 출력하지 않고 종료한다.
+// 복호화한 값에 줄바꿈(\n, \r)이 들어 있으면 입력과 출력의 줄이 어긋나므로 그 줄은 실패로 처리한다.
+//
+// 키 옵션은 keys.go 참고.
+//
+//	export CMPCRYPT_KEY=$(cmpcrypt keygen)
+//	echo -n 'db-password' | cmpcrypt encrypt -key-id db-2024
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/hsjahng/cmp-common/crypto"
+)
+
+const (
+	// DefaultBatchSize 한 번에 대량 API 로 넘기는 줄 수
+	DefaultBatchSize = 1000
+	// maxLineSize 한 줄 최대 크기
+	maxLineSize = 1 << 20
+)
+
+const usage = `사용법: cmpcrypt <명령> [옵션]
+
+명령:
+  keygen   새 32바이트 키를 base64 로 출력
+  encrypt  각 줄을 암호화
+  decrypt  각 줄을 복호화
+  rotate   각 줄을 이전 키로 복호화해 새 키로 재암호화
+  inspect  각 줄의 봉투 정보(버전, 키 ID, 알고리즘 등)를 JSON 으로 출력
+
+명령별 옵션은 cmpcrypt <명령> -h 로 확인
+`
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], defaultEnvironment()); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "cmpcrypt:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run 명령 실행
+func run(ctx context.Context, args []string, env *environment) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.stderr, usage)
+		return flag.ErrHelp
+	}
+
+	commands := map[string]func(context.Context, []string, *environment) error{
+		"keygen":  runKeygen,
+		"encrypt": runEncrypt,
+		"decrypt": runDecrypt,
+		"rotate":  runRotate,
+		"inspect": runInspect,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(env.stderr, usage)
+		return fmt.Errorf("알 수 없는 명령: %s", args[0])
+	}
+	return command(ctx, args[1:], env)
+}
+
+func newFlagSet(name string, env *environment) *flag.FlagSet {
+	fs := flag.NewFlagSet("cmpcrypt "+name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	return fs
+}
+
+func runKeygen(_ context.Context, args []string, env *environment) error {
+	fs := newFlagSet("keygen", env)
+	out := fs.String("out", "", "키를 저장할 파일 (권한 0600, 기본 stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	defer key.Destroy()
+
+	if len(*out) == 0 {
+		_, err = fmt.Fprintln(env.stdout, encodeKey(key))
+		return err
+	}
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("키 파일 생성 실패: %w", err)
+	}
+	if _, err = fmt.Fprintln(f, encodeKey(key)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runEncrypt(ctx context.Context, args []string, env *environment) error {
+	fs := newFlagSet("encrypt", env)
+	key := keySource{name: "암호화"}
+	key.register(fs, "", DefaultKeyEnv)
+	algorithm := fs.String("algorithm", crypto.AlgorithmAES256GCM.String(), "암호화 알고리즘 (AES-256-GCM, ChaCha20-Poly1305, XChaCha20-Poly1305)")
+	lines := registerLineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	alg, err := parseAlgorithm(*algorithm)
+	if err != nil {
+		return err
+	}
+	secret, err := key.load(env)
+	if err != nil {
+		return err
+	}
+	defer secret.Destroy()
+
+	c, err := crypto.NewCipherWithConfig(secret.Bytes(), crypto.CipherConfig{KeyID: key.keyID, Algorithm: alg})
+	if err != nil {
+		return err
+	}
+
+	return lines.process(env, func(values []string) ([]string, []error) {
+		results, err := c.BulkEncrypt(ctx, values, lines.concurrency)
+		outputs, errs := make([]string, len(values)), make([]error, len(values))
+		for i, r := range results {
+			outputs[i], errs[i] = r.Encrypted, r.Error
+		}
+		return outputs, fillErrors(outputs, errs, err)
+	})
+}
+
+func runDecrypt(ctx context.Context, args []string, env *environment) error {
+	fs := newFlagSet("decrypt", env)
+	key := keySource{name: "복호화"}
+	key.register(fs, "", DefaultKeyEnv)
+	lines := registerLineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	secret, err := key.load(env)
+	if err != nil {
+		return err
+	}
+	defer secret.Destroy()
+
+	c, err := crypto.NewCipherWithKeyID(secret.Bytes(), key.keyID)
+	if err != nil {
+		return err
+	}
+
+	return lines.process(env, func(values []string) ([]string, []error) {
+		results, err := c.BulkDecrypt(ctx, values, lines.concurrency)
+		outputs, errs := make([]string, len(values)), make([]error, len(values))
+		for i, r := range results {
+			outputs[i], errs[i] = r.Decrypted, r.Error
+		}
+		return outputs, fillErrors(outputs, errs, err)
+	})
+}
+
+func runRotate(ctx context.Context, args []string, env *environment) error {
+	fs := newFlagSet("rotate", env)
+	oldKey := keySource{name: "이전"}
+	oldKey.register(fs, "", DefaultKeyEnv)
+	newKey := keySource{name: "새"}
+	newKey.register(fs, "new-", DefaultNewKeyEnv)
+	algorithm := fs.String("algorithm", crypto.AlgorithmAES256GCM.String(), "새 키의 암호화 알고리즘")
+	lines := registerLineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if len(newKey.keyID) == 0 {
+		return errors.New("-new-key-id 는 비어있을 수 없습니다 (이전 키와 구분하기 위해 필요)")
+	}
+	oldKeyID := oldKey.keyID
+	if len(oldKeyID) == 0 {
+		// 키 ID 없이 암호화된 값은 키링의 모든 키로 시도하므로 이름만 붙인다
+		oldKeyID = "previous"
+	}
+	if oldKeyID == newKey.keyID {
+		return fmt.Errorf("이전 키와 새 키의 키 ID 가 같습니다: %s", oldKeyID)
+	}
+	alg, err := parseAlgorithm(*algorithm)
+	if err != nil {
+		return err
+	}
+
+	oldSecret, err := oldKey.load(env)
+	if err != nil {
+		return err
+	}
+	defer oldSecret.Destroy()
+	newSecret, err := newKey.load(env)
+	if err != nil {
+		return err
+	}
+	defer newSecret.Destroy()
+
+	ring, err := crypto.NewKeyring(oldKeyID, oldSecret.Bytes())
+	if err != nil {
+		return err
+	}
+	if err = ring.AddWithAlgorithm(newKey.keyID, newSecret.Bytes(), alg); err != nil {
+		return err
+	}
+	if err = ring.SetPrimary(newKey.keyID); err != nil {
+		return err
+	}
+
+	return lines.process(env, func(values []string) ([]string, []error) {
+		results, err := ring.ReencryptContext(ctx, values, lines.concurrency)
+		outputs, errs := make([]string, len(values)), make([]error, len(values))
+		for i, r := range results {
+			outputs[i], errs[i] = r.Reencrypted, r.Error
+		}
+		return outputs, fillErrors(outputs, errs, err)
+	})
+}
+
+// envelopeInfo inspect 출력 한 줄
+type envelopeInfo struct {
+	Format         string `json:"format"` // envelope, deterministic, legacy, invalid
+	Version        byte   `json:"version,omitempty"`
+	KeyID          string `json:"keyId,omitempty"`
+	Algorithm      string `json:"algorithm,omitempty"`
+	NonceSize      int    `json:"nonceSize,omitempty"`
+	WrappedKeySize int    `json:"wrappedKeySize,omitempty"`
+	CiphertextSize int    `json:"ciphertextSize,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+func inspect(text string) envelopeInfo {
+	if !crypto.IsEnvelope(text) {
+		return envelopeInfo{Format: "legacy"}
+	}
+	env, err := crypto.ParseEnvelope(text)
+	if err != nil {
+		return envelopeInfo{Format: "invalid", Error: err.Error()}
+	}
+
+	info := envelopeInfo{
+		Format:         "envelope",
+		Version:        env.Version,
+		KeyID:          env.KeyID,
+		Algorithm:      env.Algorithm.String(),
+		NonceSize:      len(env.Nonce),
+		WrappedKeySize: len(env.WrappedKey),
+		CiphertextSize: len(env.Ciphertext),
+	}
+	if crypto.IsDeterministic(text) {
+		info.Format = "deterministic"
+	}
+	return info
+}
+
+func runInspect(_ context.Context, args []string, env *environment) error {
+	fs := newFlagSet("inspect", env)
+	lines := registerLineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return lines.process(env, func(values []string) ([]string, []error) {
+		outputs := make([]string, len(values))
+		for i, value := range values {
+			encoded, _ := json.Marshal(inspect(value))
+			outputs[i] = string(encoded)
+		}
+		return outputs, make([]error, len(values))
+	})
+}
+
+// parseAlgorithm 알고리즘 이름 (대소문자 무시)
+func parseAlgorithm(name string) (crypto.Algorithm, error) {
+	for id := 1; id <= 255; id++ {
+		alg := crypto.Algorithm(id)
+		if alg.NonceSize() > 0 && strings.EqualFold(alg.String(), name) {
+			return alg, nil
+		}
+	}
+	return 0, fmt.Errorf("알 수 없는 알고리즘: %s", name)
+}
+
+// lineOptions 줄 단위 입출력 옵션
+type lineOptions struct {
+	in          string
+	out         string
+	batch       int
+	concurrency int
+}
+
+func registerLineFlags(fs *flag.FlagSet) *lineOptions {
+	o := &lineOptions{}
+	fs.StringVar(&o.in, "in", "", "입력 파일 (기본 stdin)")
+	fs.StringVar(&o.out, "out", "", "출력 파일 (권한 0600, 기본 stdout)")
+	fs.IntVar(&o.batch, "batch", DefaultBatchSize, "대량 API 로 한 번에 처리할 줄 수")
+	fs.IntVar(&o.concurrency, "concurrency", runtime.NumCPU(), "동시 처리 수")
+	return o
+}
+
+// process 입력을 batch 줄씩 읽어 fn 으로 변환하고 출력, 빈 줄은 fn 에 넘기지 않는다
+func (o *lineOptions) process(env *environment, fn func(values []string) ([]string, []error)) (err error) {
+	in := env.stdin
+	if len(o.in) > 0 {
+		f, err := os.Open(o.in)
+		if err != nil {
+			return fmt.Errorf("입력 파일 열기 실패: %w", err)
+		}
+		defer f.Close()
+		in = f
+
+		// 출력 파일을 열면서 잘라내므로 입력과 같은 파일이면 읽기 전에 내용이 사라진다
+		if len(o.out) > 0 {
+			inStat, err := f.Stat()
+			if err != nil {
+				return fmt.Errorf("입력 파일 확인 실패: %w", err)
+			}
+			if outStat, err := os.Stat(o.out); err == nil && os.SameFile(inStat, outStat) {
+				return errors.New("입력 파일과 출력 파일이 같습니다")
+			}
+		}
+	}
+
+	out := env.stdout
+	if len(o.out) > 0 {
+		f, err := os.OpenFile(o.out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("출력 파일 열기 실패: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	defer func() {
+		if flushErr := w.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	batch := max(o.batch, 1)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	lineNo := 0
+	lines := make([]string, 0, batch)
+	flush := func() error {
+		if err := o.writeBatch(env, w, lineNo-len(lines), lines, fn); err != nil {
+			return err
+		}
+		lines = lines[:0]
+		return nil
+	}
+	for scanner.Scan() {
+		lineNo++
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+		if len(lines) == batch {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("입력 읽기 실패: %w", err)
+	}
+	return flush()
+}
+
+// writeBatch 한 묶음 처리, 실패하거나 출력에 줄바꿈이 있는 줄이 있으면 출력하지 않고 오류 반환
+func (o *lineOptions) writeBatch(env *environment, w io.Writer, offset int, lines []string, fn func([]string) ([]string, []error)) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var indexes []int
+	var values []string
+	for i, line := range lines {
+		if len(line) > 0 {
+			indexes = append(indexes, i)
+			values = append(values, line)
+		}
+	}
+
+	outputs := make([]string, len(lines))
+	if len(values) > 0 {
+		results, errs := fn(values)
+		failed := 0
+		for j, err := range errs {
+			if err == nil && strings.ContainsAny(results[j], "\r\n") {
+				err = errors.New("출력 값에 줄바꿈이 들어 있어 한 줄로 쓸 수 없습니다")
+			}
+			if err != nil {
+				fmt.Fprintf(env.stderr, "%d번째 줄: %v\n", offset+indexes[j]+1, err)
+				failed++
+				continue
+			}
+			outputs[indexes[j]] = results[j]
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d개 줄 처리 실패", failed)
+		}
+	}
+
+	for _, output := range outputs {
+		if _, err := fmt.Fprintln(w, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillErrors 대량 처리 자체가 실패(취소 등)하면 결과가 없는 항목에 그 오류를 채운다
+func fillErrors(outputs []string, errs []error, err error) []error {
+	if err == nil {
+		return errs
+	}
+	for i := range errs {
+		if errs[i] == nil && len(outputs[i]) == 0 {
+			errs[i] = err
+		}
+	}
+	return errs
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hsjahng/cmp-common/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnvironment stdin 과 환경 변수를 지정한 environment
+func testEnvironment(stdin string, vars map[string]string) (*environment, *bytes.Buffer, *bytes.Buffer) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	return &environment{
+		stdin:  strings.NewReader(stdin),
+		stdout: stdout,
+		stderr: stderr,
+		getenv: func(name string) string { return vars[name] },
+	}, stdout, stderr
+}
+
+func generateTestKey(t *testing.T) string {
+	t.Helper()
+	env, stdout, _ := testEnvironment("", nil)
+	require.NoError(t, run(context.Background(), []string{"keygen"}, env))
+	return strings.TrimSpace(stdout.String())
+}
+
+func TestCommands(t *testing.T) {
+	ctx := context.Background()
+	key := generateTestKey(t)
+	vars := map[string]string{DefaultKeyEnv: key}
+
+	t.Run("EncryptDecrypt", func(t *testing.T) {
+		env, stdout, _ := testEnvironment("db-password\n\nsecond line\n", vars)
+		require.NoError(t, run(ctx, []string{"encrypt", "-key-id", "db-1", "-batch", "2"}, env))
+
+		lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+		require.Len(t, lines, 3)
+		require.True(t, crypto.IsEnvelope(lines[0]))
+		require.Empty(t, lines[1])
+		require.True(t, crypto.IsEnvelope(lines[2]))
+
+		env, stdout, _ = testEnvironment(strings.Join(lines, "\n"), vars)
+		require.NoError(t, run(ctx, []string{"decrypt"}, env))
+		require.Equal(t, "db-password\n\nsecond line\n", stdout.String())
+	})
+
+	t.Run("Algorithm", func(t *testing.T) {
+		env, stdout, _ := testEnvironment("value", vars)
+		require.NoError(t, run(ctx, []string{"encrypt", "-algorithm", "xchacha20-poly1305"}, env))
+
+		env, inspected, _ := testEnvironment(stdout.String(), nil)
+		require.NoError(t, run(ctx, []string{"inspect"}, env))
+		var info envelopeInfo
+		require.NoError(t, json.Unmarshal(inspected.Bytes(), &info))
+		require.Equal(t, "XChaCha20-Poly1305", info.Algorithm)
+		require.Equal(t, 24, info.NonceSize)
+
+		env, _, _ = testEnvironment("value", vars)
+		require.Error(t, run(ctx, []string{"encrypt", "-algorithm", "rot13"}, env))
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		oldKey, err := decodeKey([]byte(key))
+		require.NoError(t, err)
+		legacy, err := crypto.Encrypt("old-value", oldKey.Bytes())
+		require.NoError(t, err)
+
+		newKey := generateTestKey(t)
+		rotateVars := map[string]string{DefaultKeyEnv: key, DefaultNewKeyEnv: newKey}
+		env, stdout, _ := testEnvironment(legacy+"\n", rotateVars)
+		require.NoError(t, run(ctx, []string{"rotate", "-new-key-id", "db-2"}, env))
+
+		rotated := strings.TrimSpace(stdout.String())
+		require.Equal(t, "db-2", inspect(rotated).KeyID)
+
+		env, stdout, _ = testEnvironment(rotated, map[string]string{DefaultKeyEnv: newKey})
+		require.NoError(t, run(ctx, []string{"decrypt"}, env))
+		require.Equal(t, "old-value\n", stdout.String())
+
+		env, _, _ = testEnvironment(legacy, rotateVars)
+		require.Error(t, run(ctx, []string{"rotate"}, env))
+
+		// 취소되면 재암호화하지 않고 실패
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		env, stdout, stderr := testEnvironment(legacy+"\n", rotateVars)
+		require.Error(t, run(cancelled, []string{"rotate", "-new-key-id", "db-2"}, env))
+		require.Contains(t, stderr.String(), context.Canceled.Error())
+		require.Empty(t, strings.TrimSpace(stdout.String()))
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		dir := t.TempDir()
+		keyFile := filepath.Join(dir, "key.txt")
+		in := filepath.Join(dir, "in.txt")
+		encrypted := filepath.Join(dir, "encrypted.txt")
+		decrypted := filepath.Join(dir, "decrypted.txt")
+
+		env, _, _ := testEnvironment("", nil)
+		require.NoError(t, run(ctx, []string{"keygen", "-out", keyFile}, env))
+		stat, err := os.Stat(keyFile)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+		require.Error(t, run(ctx, []string{"keygen", "-out", keyFile}, env))
+
+		require.NoError(t, os.WriteFile(in, []byte("a\r\nb\n"), 0600))
+		require.NoError(t, run(ctx, []string{"encrypt", "-key-file", keyFile, "-in", in, "-out", encrypted}, env))
+		require.NoError(t, run(ctx, []string{"decrypt", "-key-file", keyFile, "-in", encrypted, "-out", decrypted}, env))
+
+		plain, err := os.ReadFile(decrypted)
+		require.NoError(t, err)
+		require.Equal(t, "a\nb\n", string(plain))
+
+		// 같은 파일에 덮어쓰면 입력이 잘려 나가므로 거부하고 내용을 보존한다
+		before, err := os.ReadFile(encrypted)
+		require.NoError(t, err)
+		require.Error(t, run(ctx, []string{"decrypt", "-key-file", keyFile, "-in", encrypted, "-out", encrypted}, env))
+		after, err := os.ReadFile(encrypted)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+
+	t.Run("FailedBatch", func(t *testing.T) {
+		env, stdout, _ := testEnvironment("value\n", vars)
+		require.NoError(t, run(ctx, []string{"encrypt"}, env))
+		valid := strings.TrimSpace(stdout.String())
+
+		env, stdout, stderr := testEnvironment(valid+"\n"+valid+"\nnot-encrypted\n"+valid+"\n", vars)
+		require.Error(t, run(ctx, []string{"decrypt", "-batch", "2"}, env))
+		require.Equal(t, "value\nvalue\n", stdout.String())
+		require.Contains(t, stderr.String(), "3번째 줄")
+	})
+
+	t.Run("MultilinePlaintext", func(t *testing.T) {
+		secret, err := decodeKey([]byte(key))
+		require.NoError(t, err)
+		pem, err := crypto.Encrypt("-----BEGIN KEY-----\nabc\r\n-----END KEY-----", secret.Bytes())
+		require.NoError(t, err)
+		valid, err := crypto.Encrypt("value", secret.Bytes())
+		require.NoError(t, err)
+
+		// 줄바꿈이 든 평문은 줄 대응이 깨지므로 그 줄을 실패로 처리
+		env, stdout, stderr := testEnvironment(valid+"\n"+pem+"\n", vars)
+		require.Error(t, run(ctx, []string{"decrypt", "-batch", "1"}, env))
+		require.Equal(t, "value\n", stdout.String())
+		require.Contains(t, stderr.String(), "2번째 줄")
+	})
+
+	t.Run("Inspect", func(t *testing.T) {
+		deterministic, err := crypto.EncryptDeterministic("value", crypto.CreateKeyFromString("k"))
+		require.NoError(t, err)
+
+		require.Equal(t, "legacy", inspect("bm90LWFuLWVudmVsb3Bl").Format)
+		require.Equal(t, "invalid", inspect("cmp:!!").Format)
+		require.Equal(t, "deterministic", inspect(deterministic).Format)
+		require.Equal(t, crypto.AlgorithmAES256SIV.String(), inspect(deterministic).Algorithm)
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		env, _, stderr := testEnvironment("", nil)
+		require.Error(t, run(ctx, nil, env))
+		require.Contains(t, stderr.String(), "사용법")
+		require.Error(t, run(ctx, []string{"unknown"}, env))
+
+		env, _, _ = testEnvironment("value", nil)
+		require.ErrorContains(t, run(ctx, []string{"encrypt"}, env), DefaultKeyEnv)
+	})
+}
@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+// 추가 인증 데이터(AAD)
+//
+// 암호문을 문맥(프로바이더 ID, 테이블, 컬럼, 행 ID 등)에 묶는다. 같은 aad 로만 복호화되므로
+// 암호문을 다른 행이나 다른 프로바이더로 복사하면 복호화가 실패한다.
+// aad 자체는 암호문에 저장되지 않으며 복호화할 때 다시 만들어 넘겨야 한다.
+// 기존 형식 암호문은 aad 에 묶여 있지 않으므로 aad 와 함께 복호화하면 ErrMalformedCiphertext 로 거부한다.
+// 기존 값은 Keyring.ReencryptWithAAD 로 옮기고, 옮기는 동안에는 CipherConfig.AllowLegacy 로 허용할 수 있다.
+
+// NewAAD 문맥 값들로 aad 생성, 각 값 앞에 길이를 붙여 ("ab","c") 와 ("a","bc") 가 구분된다
+func NewAAD(parts ...string) []byte {
+	size := 0
+	for _, part := range parts {
+		size += 4 + len(part)
+	}
+
+	aad := make([]byte, 0, size)
+	for _, part := range parts {
+		aad = binary.BigEndian.AppendUint32(aad, uint32(len(part)))
+		aad = append(aad, part...)
+	}
+	return aad
+}
+
+// EncryptWithAAD aad 에 묶어 암호화
+func EncryptWithAAD(content string, key []byte, aad []byte) (string, error) {
+	return encryptEnvelope(content, key, "", aad)
+}
+
+// DecryptWithAAD 암호화할 때와 같은 aad 로 복호화
+func DecryptWithAAD(encryptedText string, key []byte, aad []byte) (string, error) {
+	if len(key) == 0 || len(encryptedText) == 0 {
+		return "", newError(ErrEmptyInput, "encryptedText와 key는 비어있을 수 없습니다")
+	}
+
+	return decryptEnvelope(encryptedText, func(string) ([]byte, error) {
+		return key, nil
+	}, aad)
+}
+
+// BulkEncryptWithAAD 항목별 aad 를 적용하는 동시성 제한 대량 암호화 (aads 는 contents 와 길이가 같아야 함)
+func BulkEncryptWithAAD(contents []string, aads [][]byte, key []byte, concurrencyLimit int) []EncryptionResult {
+	if len(aads) != len(contents) {
+		return aadCountEncryptionResults(contents, aads)
+	}
+
+	results, _ := bulkEncrypt(context.Background(), contents, aads, key, "", concurrencyLimit)
+	return results
+}
+
+// BulkDecryptWithAAD 항목별 aad 를 적용하는 동시성 제한 대량 복호화 (aads 는 encryptedTexts 와 길이가 같아야 함)
+func BulkDecryptWithAAD(encryptedTexts []string, aads [][]byte, key []byte, concurrencyLimit int) []DecryptionResult {
+	if len(aads) != len(encryptedTexts) {
+		return aadCountDecryptionResults(encryptedTexts, aads)
+	}
+
+	results, _ := bulkDecrypt(context.Background(), encryptedTexts, aads, key, concurrencyLimit)
+	return results
+}
+
+// itemAAD i 번째 항목의 aad (aads 가 nil 이면 nil)
+func itemAAD(aads [][]byte, i int) []byte {
+	if aads == nil {
+		return nil
+	}
+	return aads[i]
+}
+
+func aadCountError(items, aads int) error {
+	return fmt.Errorf("aad 개수가 항목 개수와 같아야 함: 항목 %d개, aad %d개", items, aads)
+}
+
+// aadCountEncryptionResults aad 개수가 맞지 않을 때 모든 항목에 같은 오류를 담은 결과
+func aadCountEncryptionResults(contents []string, aads [][]byte) []EncryptionResult {
+	results := make([]EncryptionResult, len(contents))
+	err := aadCountError(len(contents), len(aads))
+	for i, content := range contents {
+		results[i] = EncryptionResult{
+			Original: content,
+			Error:    err,
+		}
+	}
+	return results
+}
+
+// aadCountDecryptionResults aad 개수가 맞지 않을 때 모든 항목에 같은 오류를 담은 결과
+func aadCountDecryptionResults(encryptedTexts []string, aads [][]byte) []DecryptionResult {
+	results := make([]DecryptionResult, len(encryptedTexts))
+	err := aadCountError(len(encryptedTexts), len(aads))
+	for i, text := range encryptedTexts {
+		results[i] = DecryptionResult{
+			Encrypted: text,
+			Error:     err,
+		}
+	}
+	return results
+}
@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAAD(t *testing.T) {
+	key := CreateKeyFromString("aad-test-key")
+	message := "aws-secret-access-key"
+	aad := NewAAD("provider-1", "provider_account", "secret_key")
+
+	t.Run("NewAAD", func(t *testing.T) {
+		require.NotEqual(t, NewAAD("ab", "c"), NewAAD("a", "bc"))
+		require.Equal(t, NewAAD("a", "b"), NewAAD("a", "b"))
+		require.Empty(t, NewAAD())
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		encrypted, err := EncryptWithAAD(message, key, aad)
+		require.NoError(t, err)
+
+		decrypted, err := DecryptWithAAD(encrypted, key, aad)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+	})
+
+	t.Run("ContextMismatch", func(t *testing.T) {
+		encrypted, err := EncryptWithAAD(message, key, aad)
+		require.NoError(t, err)
+
+		_, err = DecryptWithAAD(encrypted, key, NewAAD("provider-2", "provider_account", "secret_key"))
+		require.Error(t, err)
+
+		_, err = Decrypt(encrypted, key)
+		require.Error(t, err)
+
+		plain, err := Encrypt(message, key)
+		require.NoError(t, err)
+		_, err = DecryptWithAAD(plain, key, aad)
+		require.Error(t, err)
+	})
+
+	t.Run("LegacyRejectedWithAAD", func(t *testing.T) {
+		legacy := encryptLegacy(t, message, key)
+
+		// 다른 행에 복사한 기존 형식 값은 aad 를 넘기면 복호화되지 않는다
+		_, err := DecryptWithAAD(legacy, key, NewAAD("accounts", "secret", "row-B"))
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+
+		ring, err := NewKeyring("k1", key)
+		require.NoError(t, err)
+		_, err = ring.DecryptWithAAD(legacy, NewAAD("accounts", "secret", "row-B"))
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+		require.ErrorIs(t, ring.BulkDecryptWithAAD([]string{legacy}, [][]byte{aad}, 1)[0].Error, ErrMalformedCiphertext)
+
+		// aad 없이 복호화하거나 AllowLegacy 를 켜면 허용
+		decrypted, err := ring.Decrypt(legacy)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+
+		config := DefaultCipherConfig()
+		config.AllowLegacy = true
+		c, err := NewCipherWithConfig(key, config)
+		require.NoError(t, err)
+		decrypted, err = c.DecryptWithAAD(legacy, aad)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		contents := []string{"a", "b", "c"}
+		aads := [][]byte{NewAAD("row", "1"), NewAAD("row", "2"), NewAAD("row", "3")}
+
+		encResults := BulkEncryptWithAAD(contents, aads, key, 2)
+		encrypted := make([]string, len(encResults))
+		for i, r := range encResults {
+			require.NoError(t, r.Error)
+			encrypted[i] = r.Encrypted
+		}
+
+		for i, r := range BulkDecryptWithAAD(encrypted, aads, key, 2) {
+			require.NoError(t, r.Error)
+			require.Equal(t, contents[i], r.Decrypted)
+		}
+
+		// 행을 뒤바꾸면 복호화 실패
+		swapped := []string{encrypted[1], encrypted[0], encrypted[2]}
+		results := BulkDecryptWithAAD(swapped, aads, key, 2)
+		require.Error(t, results[0].Error)
+		require.Error(t, results[1].Error)
+		require.NoError(t, results[2].Error)
+
+		for _, r := range BulkEncryptWithAAD(contents, aads[:2], key, 2) {
+			require.Error(t, r.Error)
+		}
+	})
+
+	t.Run("Keyring", func(t *testing.T) {
+		ring, err := NewKeyring("k1", key)
+		require.NoError(t, err)
+
+		encrypted, err := ring.EncryptWithAAD(message, aad)
+		require.NoError(t, err)
+		decrypted, err := ring.DecryptWithAAD(encrypted, aad)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+		_, err = ring.Decrypt(encrypted)
+		require.Error(t, err)
+
+		require.NoError(t, ring.Rotate("k2", CreateKeyFromString("aad-rotated")))
+		results := ring.ReencryptWithAAD([]string{encrypted, encryptLegacy(t, message, key)}, [][]byte{aad, aad}, 2)
+		for _, r := range results {
+			require.NoError(t, r.Error)
+			decrypted, err := ring.DecryptWithAAD(r.Reencrypted, aad)
+			require.NoError(t, err)
+			require.Equal(t, message, decrypted)
+			require.Equal(t, "k2", envelopeKeyID(r.Reencrypted))
+		}
+
+		bulk := ring.BulkDecryptWithAAD([]string{results[0].Reencrypted}, [][]byte{aad}, 1)
+		require.NoError(t, bulk[0].Error)
+		require.Equal(t, message, bulk[0].Decrypted)
+	})
+}
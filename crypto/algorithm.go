@@ -0,0 +1,132 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// 알고리즘 레지스트리
+//
+// 봉투에는 알고리즘 ID 가 기록되므로 복호화는 암호문을 만든 알고리즘을 자동으로 고른다.
+// 모든 알고리즘은 32바이트 키를 사용한다.
+//
+//   - AES-256-GCM: 기본값, 12바이트 랜덤 nonce 이므로 키 하나로 약 2^32 건까지만 안전하게 암호화할 수 있다
+//   - ChaCha20-Poly1305: AES 하드웨어 가속이 없는 환경용, nonce 제약은 AES-256-GCM 과 같다
+//   - XChaCha20-Poly1305: 24바이트 nonce 라 랜덤 nonce 충돌을 걱정하지 않고 키 하나로 대량 암호화할 수 있다
+//   - AES-256-SIV: 결정적 암호화 전용 (DeterministicCipher), Cipher 로는 사용할 수 없다
+
+// AEADFactory 32바이트 키로 AEAD 생성
+type AEADFactory func(key []byte) (cipher.AEAD, error)
+
+type algorithmSpec struct {
+	name      string
+	nonceSize int
+	factory   AEADFactory // nil 이면 Cipher 로 사용할 수 없는 알고리즘
+}
+
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = map[Algorithm]algorithmSpec{
+		AlgorithmAES256GCM:         {name: "AES-256-GCM", nonceSize: 12, factory: newGCM},
+		AlgorithmAES256SIV:         {name: "AES-256-SIV", nonceSize: 16},
+		AlgorithmChaCha20Poly1305:  {name: "ChaCha20-Poly1305", nonceSize: chacha20poly1305.NonceSize, factory: newChaCha20Poly1305},
+		AlgorithmXChaCha20Poly1305: {name: "XChaCha20-Poly1305", nonceSize: chacha20poly1305.NonceSizeX, factory: newXChaCha20Poly1305},
+	}
+)
+
+// RegisterAlgorithm 새 AEAD 알고리즘 등록 (이미 있는 ID 면 오류)
+//
+// ID 는 암호문에 기록되므로 한 번 배포한 ID 는 다른 알고리즘에 다시 쓰면 안 된다.
+// 이미 만든 Cipher 에는 적용되지 않으므로 init 등에서 Cipher 를 만들기 전에 등록한다.
+func RegisterAlgorithm(id Algorithm, name string, nonceSize int, factory AEADFactory) error {
+	if id == 0 || len(name) == 0 || nonceSize <= 0 || factory == nil {
+		return newError(ErrEmptyInput, "알고리즘 ID, 이름, nonce 크기, factory 는 비어있을 수 없습니다")
+	}
+
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+
+	if spec, ok := algorithms[id]; ok {
+		return fmt.Errorf("이미 등록된 알고리즘 ID: %d (%s)", byte(id), spec.name)
+	}
+	algorithms[id] = algorithmSpec{name: name, nonceSize: nonceSize, factory: factory}
+	return nil
+}
+
+func lookupAlgorithm(a Algorithm) (algorithmSpec, bool) {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+	spec, ok := algorithms[a]
+	return spec, ok
+}
+
+// registeredAlgorithms Cipher 로 사용할 수 있는 (factory 가 있는) 알고리즘 목록
+func registeredAlgorithms() []Algorithm {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+	ids := make([]Algorithm, 0, len(algorithms))
+	for id, spec := range algorithms {
+		if spec.factory != nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (a Algorithm) String() string {
+	if spec, ok := lookupAlgorithm(a); ok {
+		return spec.name
+	}
+	return fmt.Sprintf("Algorithm(%d)", byte(a))
+}
+
+// NonceSize 알고리즘별 nonce 길이, 알 수 없는 알고리즘이면 0
+func (a Algorithm) NonceSize() int {
+	spec, _ := lookupAlgorithm(a)
+	return spec.nonceSize
+}
+
+// checkEnvelopeAlgorithm 암호문에서 읽은 알고리즘이 등록된 AEAD 알고리즘인지 확인
+func checkEnvelopeAlgorithm(algorithm Algorithm) error {
+	if spec, ok := lookupAlgorithm(algorithm); !ok || spec.factory == nil {
+		return newError(ErrMalformedCiphertext, "지원하지 않는 알고리즘: %s", algorithm)
+	}
+	return nil
+}
+
+// newAEAD 등록된 알고리즘의 AEAD 생성, 알고리즘이 설정에서 왔으므로 지원하지 않으면 ErrInvalidConfig
+//
+// 암호문에서 읽은 알고리즘은 먼저 checkEnvelopeAlgorithm 으로 확인한다.
+func newAEAD(algorithm Algorithm, key []byte) (cipher.AEAD, error) {
+	spec, ok := lookupAlgorithm(algorithm)
+	if !ok || spec.factory == nil {
+		return nil, newError(ErrInvalidConfig, "지원하지 않는 알고리즘: %s", algorithm)
+	}
+	aead, err := spec.factory(key)
+	if err != nil {
+		return nil, err
+	}
+	if aead.NonceSize() != spec.nonceSize {
+		return nil, fmt.Errorf("%s nonce 크기가 등록된 값과 다릅니다: %d", spec.name, aead.NonceSize())
+	}
+	return aead, nil
+}
+
+func newChaCha20Poly1305(key []byte) (cipher.AEAD, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("ChaCha20-Poly1305 초기화 실패: %w", err)
+	}
+	return aead, nil
+}
+
+func newXChaCha20Poly1305(key []byte) (cipher.AEAD, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("XChaCha20-Poly1305 초기화 실패: %w", err)
+	}
+	return aead, nil
+}
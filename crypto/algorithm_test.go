@@ -0,0 +1,172 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func TestAlgorithms(t *testing.T) {
+	key := CreateKeyFromString("algorithm-test-key")
+
+	t.Run("EncryptDecrypt", func(t *testing.T) {
+		for _, algorithm := range []Algorithm{AlgorithmAES256GCM, AlgorithmChaCha20Poly1305, AlgorithmXChaCha20Poly1305} {
+			t.Run(algorithm.String(), func(t *testing.T) {
+				encrypted, err := EncryptWithAlgorithm("민감한 값", key, algorithm)
+				require.NoError(t, err)
+
+				env, err := ParseEnvelope(encrypted)
+				require.NoError(t, err)
+				require.Equal(t, algorithm, env.Algorithm)
+				require.Len(t, env.Nonce, algorithm.NonceSize())
+
+				// 복호화는 봉투의 알고리즘을 자동으로 고른다
+				decrypted, err := Decrypt(encrypted, key)
+				require.NoError(t, err)
+				require.Equal(t, "민감한 값", decrypted)
+
+				_, err = Decrypt(encrypted, CreateKeyFromString("other-key"))
+				require.ErrorIs(t, err, ErrAuthenticationFailed)
+			})
+		}
+	})
+
+	t.Run("NonceSizes", func(t *testing.T) {
+		require.Equal(t, 12, AlgorithmAES256GCM.NonceSize())
+		require.Equal(t, 12, AlgorithmChaCha20Poly1305.NonceSize())
+		require.Equal(t, 24, AlgorithmXChaCha20Poly1305.NonceSize())
+		require.Equal(t, "XChaCha20-Poly1305", AlgorithmXChaCha20Poly1305.String())
+		require.Equal(t, 0, Algorithm(200).NonceSize())
+		require.Equal(t, "Algorithm(200)", Algorithm(200).String())
+	})
+
+	t.Run("CipherDispatch", func(t *testing.T) {
+		gcm, err := NewCipher(key)
+		require.NoError(t, err)
+		require.Equal(t, AlgorithmAES256GCM, gcm.Algorithm())
+
+		config := DefaultCipherConfig()
+		config.Algorithm = AlgorithmXChaCha20Poly1305
+		xchacha, err := NewCipherWithConfig(key, config)
+		require.NoError(t, err)
+
+		// 같은 키면 어느 Cipher 로 만든 암호문이든 서로 복호화
+		fromX, err := xchacha.EncryptWithAAD("값", []byte("aad"))
+		require.NoError(t, err)
+		decrypted, err := gcm.DecryptWithAAD(fromX, []byte("aad"))
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+
+		fromGCM, err := gcm.Encrypt("값")
+		require.NoError(t, err)
+		decrypted, err = xchacha.Decrypt(fromGCM)
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+
+		// 재사용하는 Cipher 는 등록된 알고리즘을 모두, 일회성 Cipher 는 필요한 알고리즘만 만든다
+		require.Len(t, gcm.aeads, len(registeredAlgorithms()))
+		env, err := ParseEnvelope(fromX)
+		require.NoError(t, err)
+		oneShot, err := newOpenCipher(key, env)
+		require.NoError(t, err)
+		require.Len(t, oneShot.aeads, 1)
+		require.Contains(t, oneShot.aeads, AlgorithmXChaCha20Poly1305)
+	})
+
+	t.Run("AlgorithmBoundToHeader", func(t *testing.T) {
+		encrypted, err := EncryptWithAlgorithm("값", key, AlgorithmChaCha20Poly1305)
+		require.NoError(t, err)
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+
+		env.Algorithm = AlgorithmAES256GCM
+		_, err = Decrypt(env.Encode(), key)
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+	})
+
+	t.Run("KeyringAlgorithm", func(t *testing.T) {
+		ring, err := NewKeyring("v1", key)
+		require.NoError(t, err)
+		require.NoError(t, ring.AddWithAlgorithm("v2", CreateKeyFromString("v2"), AlgorithmXChaCha20Poly1305))
+
+		old, err := ring.Encrypt("값")
+		require.NoError(t, err)
+		require.NoError(t, ring.SetPrimary("v2"))
+
+		encrypted, err := ring.Encrypt("값")
+		require.NoError(t, err)
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		require.Equal(t, AlgorithmXChaCha20Poly1305, env.Algorithm)
+
+		for _, text := range []string{old, encrypted} {
+			decrypted, err := ring.Decrypt(text)
+			require.NoError(t, err)
+			require.Equal(t, "값", decrypted)
+		}
+	})
+
+	t.Run("UnsupportedAlgorithm", func(t *testing.T) {
+		// 설정에서 온 알고리즘은 설정 오류
+		_, err := NewCipherWithConfig(key, CipherConfig{KeyID: "x", Algorithm: Algorithm(200)})
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		require.NotErrorIs(t, err, ErrMalformedCiphertext)
+
+		ring, err := NewKeyring("v1", key)
+		require.NoError(t, err)
+		err = ring.AddWithAlgorithm("siv", key, AlgorithmAES256SIV)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		require.NotErrorIs(t, err, ErrMalformedCiphertext)
+
+		// 0 이면 AES-256-GCM
+		c, err := NewCipherWithConfig(key, CipherConfig{KeyID: "x"})
+		require.NoError(t, err)
+		require.Equal(t, AlgorithmAES256GCM, c.Algorithm())
+
+		// 암호문에서 읽은 알고리즘은 형식 오류
+		encrypted, err := c.Encrypt("값")
+		require.NoError(t, err)
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		env.Algorithm = Algorithm(200)
+		_, err = Decrypt(env.Encode(), key)
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		factory := func(key []byte) (cipher.AEAD, error) { return chacha20poly1305.NewX(key) }
+
+		require.Error(t, RegisterAlgorithm(AlgorithmAES256GCM, "dup", 12, factory))
+		require.ErrorIs(t, RegisterAlgorithm(0, "zero", 12, factory), ErrEmptyInput)
+
+		custom, mismatched := Algorithm(250), Algorithm(251)
+		t.Cleanup(func() {
+			algorithmsMu.Lock()
+			delete(algorithms, custom)
+			delete(algorithms, mismatched)
+			algorithmsMu.Unlock()
+		})
+		require.NoError(t, RegisterAlgorithm(custom, "Custom-XChaCha", chacha20poly1305.NonceSizeX, factory))
+		encrypted, err := EncryptWithAlgorithm("값", key, custom)
+		require.NoError(t, err)
+		decrypted, err := Decrypt(encrypted, key)
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+
+		// 등록된 nonce 크기와 다른 AEAD 는 거부
+		require.NoError(t, RegisterAlgorithm(mismatched, "Mismatched", 12, factory))
+		_, err = EncryptWithAlgorithm("값", key, mismatched)
+		require.Error(t, err)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := EncryptWithAlgorithm("값", key, AlgorithmAES256SIV)
+		require.Error(t, err)
+		_, err = EncryptWithAlgorithm("값", key, Algorithm(200))
+		require.Error(t, err)
+		_, err = EncryptWithAlgorithm("값", key[:16], AlgorithmXChaCha20Poly1305)
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+	})
+}
@@ -0,0 +1,113 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// 블라인드 인덱스 (HMAC-SHA256 토큰)
+//
+// 암호화된 컬럼 옆에 정규화한 평문의 HMAC 을 잘라 저장해 두면
+// 테이블을 복호화하지 않고 WHERE email_bidx = ? 로 조회할 수 있다.
+// 결정적 암호화와 달리 토큰에서 평문을 되돌릴 수 없고, 길이를 줄이면 충돌이 늘어나는 대신 노출되는 정보가 줄어든다.
+// 잘린 토큰은 충돌할 수 있으므로 조회 결과는 복호화한 값으로 다시 확인해야 한다.
+//
+// HMAC 키는 암호화 키와 반드시 다른 키를 사용한다.
+
+const (
+	// DefaultBlindIndexLength 기본 토큰 길이 (바이트)
+	DefaultBlindIndexLength = 16
+	// MinBlindIndexLength 최소 토큰 길이 (바이트)
+	MinBlindIndexLength = 4
+)
+
+// Normalizer 인덱스를 만들기 전에 값을 정규화하는 함수
+type Normalizer func(string) string
+
+var (
+	// NormalizeTrim 앞뒤 공백 제거
+	NormalizeTrim Normalizer = strings.TrimSpace
+	// NormalizeLowercase 소문자로 변환
+	NormalizeLowercase Normalizer = strings.ToLower
+)
+
+// BlindIndexConfig 블라인드 인덱스 설정
+type BlindIndexConfig struct {
+	Context     string       // 컬럼 등 용도 구분자, 같은 값이라도 용도가 다르면 다른 토큰이 된다
+	Length      int          // 토큰 길이 (바이트, MinBlindIndexLength ~ sha256.Size)
+	Normalizers []Normalizer // 순서대로 적용할 정규화 함수
+}
+
+// DefaultBlindIndexConfig 기본 블라인드 인덱스 설정 (16바이트, 공백 제거 후 소문자 변환)
+func DefaultBlindIndexConfig() BlindIndexConfig {
+	return BlindIndexConfig{
+		Length:      DefaultBlindIndexLength,
+		Normalizers: []Normalizer{NormalizeTrim, NormalizeLowercase},
+	}
+}
+
+// BlindIndexer 블라인드 인덱스 생성기, 여러 고루틴에서 동시에 사용해도 안전하다
+type BlindIndexer struct {
+	key    []byte
+	config BlindIndexConfig
+}
+
+// NewBlindIndexer HMAC 키로 BlindIndexer 생성
+func NewBlindIndexer(hmacKey []byte, config BlindIndexConfig) (*BlindIndexer, error) {
+	if len(hmacKey) < KeySize {
+		return nil, &KeySizeError{Want: KeySize, Got: len(hmacKey), AtLeast: true}
+	}
+	if config.Length == 0 {
+		config.Length = DefaultBlindIndexLength
+	}
+	if config.Length < MinBlindIndexLength || config.Length > sha256.Size {
+		return nil, fmt.Errorf("토큰 길이는 %d~%d바이트여야 함: 현재 %d 바이트", MinBlindIndexLength, sha256.Size, config.Length)
+	}
+
+	return &BlindIndexer{
+		key:    append([]byte(nil), hmacKey...),
+		config: config,
+	}, nil
+}
+
+// BlindIndex 값 하나의 블라인드 인덱스 (소문자 hex)
+func BlindIndex(value string, hmacKey []byte, config BlindIndexConfig) (string, error) {
+	b, err := NewBlindIndexer(hmacKey, config)
+	if err != nil {
+		return "", err
+	}
+	return b.Index(value)
+}
+
+// Normalize 설정된 정규화 함수를 순서대로 적용
+func (b *BlindIndexer) Normalize(value string) string {
+	for _, normalize := range b.config.Normalizers {
+		value = normalize(value)
+	}
+	return value
+}
+
+// Index 정규화한 값의 HMAC 을 잘라 소문자 hex 로 반환
+func (b *BlindIndexer) Index(value string) (string, error) {
+	normalized := b.Normalize(value)
+	if len(normalized) == 0 {
+		return "", newError(ErrEmptyInput, "정규화한 값이 비어있습니다")
+	}
+
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write(NewAAD(b.config.Context, normalized))
+	return hex.EncodeToString(mac.Sum(nil)[:b.config.Length]), nil
+}
+
+// Match 값의 인덱스가 주어진 토큰과 같은지 상수 시간으로 비교
+func (b *BlindIndexer) Match(value string, index string) bool {
+	expected, err := b.Index(value)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(index))) == 1
+}
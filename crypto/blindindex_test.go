@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlindIndex(t *testing.T) {
+	hmacKey := CreateKeyFromString("blind-index-hmac-key")
+
+	t.Run("Normalization", func(t *testing.T) {
+		b, err := NewBlindIndexer(hmacKey, DefaultBlindIndexConfig())
+		require.NoError(t, err)
+
+		a, err := b.Index("  User@Example.COM ")
+		require.NoError(t, err)
+		c, err := b.Index("user@example.com")
+		require.NoError(t, err)
+		require.Equal(t, a, c)
+		require.Len(t, a, 2*DefaultBlindIndexLength)
+
+		other, err := b.Index("other@example.com")
+		require.NoError(t, err)
+		require.NotEqual(t, a, other)
+
+		require.True(t, b.Match("USER@example.com", a))
+		require.True(t, b.Match("user@example.com", strings.ToUpper(a)))
+		require.False(t, b.Match("other@example.com", a))
+	})
+
+	t.Run("NoNormalizers", func(t *testing.T) {
+		b, err := NewBlindIndexer(hmacKey, BlindIndexConfig{Length: 8})
+		require.NoError(t, err)
+
+		a, err := b.Index("AKIAEXAMPLE")
+		require.NoError(t, err)
+		c, err := b.Index("akiaexample")
+		require.NoError(t, err)
+		require.NotEqual(t, a, c)
+		require.Len(t, a, 16)
+	})
+
+	t.Run("TruncatedHMAC", func(t *testing.T) {
+		config := DefaultBlindIndexConfig()
+		config.Context = "accounts.email"
+		config.Length = 32
+
+		token, err := BlindIndex("user@example.com", hmacKey, config)
+		require.NoError(t, err)
+
+		mac := hmac.New(sha256.New, hmacKey)
+		mac.Write(NewAAD("accounts.email", "user@example.com"))
+		require.Equal(t, hex.EncodeToString(mac.Sum(nil)), token)
+
+		config.Length = 8
+		short, err := BlindIndex("user@example.com", hmacKey, config)
+		require.NoError(t, err)
+		require.Equal(t, token[:16], short)
+	})
+
+	t.Run("ContextAndKeySeparation", func(t *testing.T) {
+		config := DefaultBlindIndexConfig()
+		config.Context = "accounts.email"
+		a, err := BlindIndex("value", hmacKey, config)
+		require.NoError(t, err)
+
+		config.Context = "accounts.access_key"
+		b, err := BlindIndex("value", hmacKey, config)
+		require.NoError(t, err)
+		require.NotEqual(t, a, b)
+
+		c, err := BlindIndex("value", CreateKeyFromString("other-hmac-key"), config)
+		require.NoError(t, err)
+		require.NotEqual(t, b, c)
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		_, err := NewBlindIndexer([]byte("short"), DefaultBlindIndexConfig())
+		require.Error(t, err)
+
+		_, err = NewBlindIndexer(hmacKey, BlindIndexConfig{Length: 2})
+		require.Error(t, err)
+		_, err = NewBlindIndexer(hmacKey, BlindIndexConfig{Length: 33})
+		require.Error(t, err)
+
+		b, err := NewBlindIndexer(hmacKey, DefaultBlindIndexConfig())
+		require.NoError(t, err)
+		_, err = b.Index("   ")
+		require.Error(t, err)
+		require.False(t, b.Match("   ", ""))
+	})
+}
@@ -0,0 +1,278 @@
+package crypto
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// 대량 암복호화 엔진
+//
+// 제한된 수의 워커가 항목을 처리하며, context 가 취소되거나 기한이 지나면 새 항목을 더 꺼내지 않는다.
+// 슬라이스 버전은 처리하지 못한 항목의 Error 에 context 오류를 담아 반환하고,
+// Seq 버전은 결과를 처리가 끝나는 순서대로 (입력 인덱스, 결과) 로 내보낸다.
+
+// DefaultConcurrencyLimit 기본 동시성 제한
+const DefaultConcurrencyLimit = 10
+
+// BulkEncryptContext 취소 가능한 동시성 제한 대량 암호화
+//
+// context 가 취소되면 처리하지 못한 항목에 context 오류를 담고 그 오류를 함께 반환한다.
+func BulkEncryptContext(ctx context.Context, contents []string, key []byte, concurrencyLimit int) ([]EncryptionResult, error) {
+	return bulkEncrypt(ctx, contents, nil, key, "", concurrencyLimit)
+}
+
+// BulkDecryptContext 취소 가능한 동시성 제한 대량 복호화
+//
+// context 가 취소되면 처리하지 못한 항목에 context 오류를 담고 그 오류를 함께 반환한다.
+func BulkDecryptContext(ctx context.Context, encryptedTexts []string, key []byte, concurrencyLimit int) ([]DecryptionResult, error) {
+	return bulkDecrypt(ctx, encryptedTexts, nil, key, concurrencyLimit)
+}
+
+// BulkEncryptSeq 입력을 순회하며 암호화 결과를 처리 순서대로 내보낸다 (키는 입력 인덱스)
+//
+// 입력은 필요한 만큼만 읽으므로 수백만 건도 메모리에 올리지 않고 처리할 수 있다.
+// context 가 취소되면 순회가 끝나며, 호출자는 ctx.Err() 로 중단 여부를 확인한다.
+// 입력 시퀀스는 별도 고루틴에서 순회되며, 순회가 끝난 뒤에도 입력이 다음 항목을 내놓거나 끝날 때까지 그 고루틴이 남는다.
+func BulkEncryptSeq(ctx context.Context, contents iter.Seq[string], key []byte, concurrencyLimit int) iter.Seq2[int, EncryptionResult] {
+	c, cipherErr := NewCipher(key)
+	return bulkSeq(ctx, contents, concurrencyLimit, func(_ int, content string) EncryptionResult {
+		if cipherErr != nil {
+			return EncryptionResult{Original: content, Error: cipherErr}
+		}
+		return c.encryptItem(content, nil)
+	})
+}
+
+// BulkDecryptSeq 입력을 순회하며 복호화 결과를 처리 순서대로 내보낸다 (키는 입력 인덱스)
+//
+// context 가 취소되면 순회가 끝나며, 호출자는 ctx.Err() 로 중단 여부를 확인한다.
+// 입력 시퀀스는 BulkEncryptSeq 와 마찬가지로 별도 고루틴에서 순회된다.
+func BulkDecryptSeq(ctx context.Context, encryptedTexts iter.Seq[string], key []byte, concurrencyLimit int) iter.Seq2[int, DecryptionResult] {
+	c, cipherErr := NewCipher(key)
+	return bulkSeq(ctx, encryptedTexts, concurrencyLimit, func(_ int, text string) DecryptionResult {
+		if cipherErr != nil {
+			return DecryptionResult{Encrypted: text, Error: cipherErr}
+		}
+		return c.decryptItem(text, nil)
+	})
+}
+
+// bulkEncrypt 키 ID 와 항목별 aad 를 적용하는 대량 암호화 (aads 가 nil 이면 aad 없음)
+func bulkEncrypt(ctx context.Context, contents []string, aads [][]byte, key []byte, keyID string, concurrencyLimit int) ([]EncryptionResult, error) {
+	// 키 스케줄은 한 번만 만들어 모든 항목에 재사용
+	c, err := NewCipherWithKeyID(key, keyID)
+	if err != nil {
+		// 키가 잘못된 경우 모든 결과에 동일한 오류 반환
+		results := make([]EncryptionResult, len(contents))
+		for i, content := range contents {
+			results[i] = EncryptionResult{Original: content, Error: err}
+		}
+		return results, nil
+	}
+	return c.bulkEncrypt(ctx, contents, aads, concurrencyLimit)
+}
+
+// bulkDecrypt 항목별 aad 를 적용하는 대량 복호화 (aads 가 nil 이면 aad 없음)
+func bulkDecrypt(ctx context.Context, encryptedTexts []string, aads [][]byte, key []byte, concurrencyLimit int) ([]DecryptionResult, error) {
+	// 키 스케줄은 한 번만 만들어 모든 항목에 재사용
+	c, err := NewCipher(key)
+	if err != nil {
+		// 키가 잘못된 경우 모든 결과에 동일한 오류 반환
+		results := make([]DecryptionResult, len(encryptedTexts))
+		for i, text := range encryptedTexts {
+			results[i] = DecryptionResult{Encrypted: text, Error: err}
+		}
+		return results, nil
+	}
+	return c.bulkDecrypt(ctx, encryptedTexts, aads, concurrencyLimit)
+}
+
+func (c *Cipher) bulkEncrypt(ctx context.Context, contents []string, aads [][]byte, concurrencyLimit int) ([]EncryptionResult, error) {
+	return bulkSlice(ctx, contents, concurrencyLimit,
+		func(i int, content string) EncryptionResult {
+			return c.encryptItem(content, itemAAD(aads, i))
+		},
+		func(content string, err error) EncryptionResult {
+			return EncryptionResult{Original: content, Error: err}
+		})
+}
+
+func (c *Cipher) bulkDecrypt(ctx context.Context, encryptedTexts []string, aads [][]byte, concurrencyLimit int) ([]DecryptionResult, error) {
+	return bulkSlice(ctx, encryptedTexts, concurrencyLimit,
+		func(i int, text string) DecryptionResult {
+			return c.decryptItem(text, itemAAD(aads, i))
+		},
+		func(text string, err error) DecryptionResult {
+			return DecryptionResult{Encrypted: text, Error: err}
+		})
+}
+
+// encryptItem 항목 하나 암호화
+func (c *Cipher) encryptItem(content string, aad []byte) EncryptionResult {
+	encrypted, err := c.EncryptWithAAD(content, aad)
+	return EncryptionResult{
+		Original:  content,
+		Encrypted: encrypted,
+		Error:     err,
+	}
+}
+
+// decryptItem 항목 하나 복호화
+func (c *Cipher) decryptItem(encryptedText string, aad []byte) DecryptionResult {
+	decrypted, err := c.DecryptWithAAD(encryptedText, aad)
+	return DecryptionResult{
+		Encrypted: encryptedText,
+		Decrypted: decrypted,
+		Error:     err,
+	}
+}
+
+// normalizeConcurrency 동시성 제한 기본값 적용
+func normalizeConcurrency(concurrencyLimit int) int {
+	if concurrencyLimit <= 0 {
+		return DefaultConcurrencyLimit
+	}
+	return concurrencyLimit
+}
+
+// bulkSlice 슬라이스 입력을 제한된 워커로 처리, 취소되면 남은 항목은 cancelled 결과로 채운다
+func bulkSlice[T, R any](ctx context.Context, inputs []T, concurrencyLimit int,
+	process func(i int, input T) R, cancelled func(input T, err error) R) ([]R, error) {
+	results := make([]R, len(inputs))
+	done := make([]bool, len(inputs))
+
+	// 작업 채널 생성
+	jobs := make(chan int)
+
+	// 동시에 처리할 고루틴 관리를 위한 WaitGroup
+	var wg sync.WaitGroup
+
+	// 제한된 수의 워커 생성
+	for w := 0; w < min(normalizeConcurrency(concurrencyLimit), len(inputs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// 분배와 취소가 겹칠 수 있으므로 처리 직전에 다시 확인
+				if ctx.Err() != nil {
+					continue
+				}
+				results[i] = process(i, inputs[i])
+				done[i] = true
+			}
+		}()
+	}
+
+	// 취소되기 전까지 작업 분배
+dispatch:
+	for i := range inputs {
+		// select 는 준비된 case 를 임의로 고르므로 취소 여부를 먼저 확인
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+
+	// 모든 고루틴이 완료될 때까지 대기
+	wg.Wait()
+
+	err := ctx.Err()
+	if err != nil {
+		for i, input := range inputs {
+			if !done[i] {
+				results[i] = cancelled(input, err)
+			}
+		}
+	}
+	return results, err
+}
+
+// bulkSeq 입력 시퀀스를 제한된 워커로 처리하고 결과를 처리 순서대로 내보낸다
+//
+// 입력은 생산자 고루틴이 순회한다. 입력이 다음 항목을 기다리며 막혀 있으면 생산자는 취소를 알 수 없으므로,
+// 워커와 소비자는 생산자를 기다리지 않고 끝나며 생산자는 입력이 다음 항목을 내놓거나 끝날 때 종료된다.
+func bulkSeq[T, R any](ctx context.Context, inputs iter.Seq[T], concurrencyLimit int, process func(i int, input T) R) iter.Seq2[int, R] {
+	return func(yield func(int, R) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type job struct {
+			index int
+			input T
+		}
+		type result struct {
+			index  int
+			result R
+		}
+
+		concurrency := normalizeConcurrency(concurrencyLimit)
+		jobs := make(chan job, concurrency)
+		results := make(chan result, concurrency)
+
+		// 입력을 읽어 작업 채널로 전달, 보내는 동안에도 취소를 확인한다
+		go func() {
+			defer close(jobs)
+			i := 0
+			for input := range inputs {
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case jobs <- job{index: i, input: input}:
+				}
+				i++
+			}
+		}()
+
+		// 제한된 수의 워커 생성
+		var wg sync.WaitGroup
+		for w := 0; w < concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					// 생산자가 입력에서 막혀 jobs 가 닫히지 않아도 취소되면 끝낸다
+					var j job
+					var ok bool
+					select {
+					case <-ctx.Done():
+						return
+					case j, ok = <-jobs:
+					}
+					if !ok || ctx.Err() != nil {
+						return
+					}
+					r := result{index: j.index, result: process(j.index, j.input)}
+					select {
+					case <-ctx.Done():
+						return
+					case results <- r:
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for r := range results {
+			if ctx.Err() != nil || !yield(r.index, r.result) {
+				break
+			}
+		}
+
+		// 중단된 경우 워커가 모두 끝날 때까지 남은 결과를 비운다
+		cancel()
+		for range results {
+		}
+	}
+}
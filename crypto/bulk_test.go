@@ -0,0 +1,185 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkEngine(t *testing.T) {
+	key := CreateKeyFromString("bulk-engine-key")
+	contents := make([]string, 500)
+	for i := range contents {
+		contents[i] = fmt.Sprintf("row-%d", i)
+	}
+
+	t.Run("Context", func(t *testing.T) {
+		encResults, err := BulkEncryptContext(context.Background(), contents, key, 4)
+		require.NoError(t, err)
+
+		encrypted := make([]string, len(encResults))
+		for i, r := range encResults {
+			require.NoError(t, r.Error)
+			encrypted[i] = r.Encrypted
+		}
+
+		decResults, err := BulkDecryptContext(context.Background(), encrypted, key, 4)
+		require.NoError(t, err)
+		for i, r := range decResults {
+			require.NoError(t, r.Error)
+			require.Equal(t, contents[i], r.Decrypted)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := BulkEncryptContext(ctx, contents, key, 4)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Len(t, results, len(contents))
+		for i, r := range results {
+			require.Equal(t, contents[i], r.Original)
+			require.ErrorIs(t, r.Error, context.Canceled)
+		}
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var processed atomic.Int32
+		results, err := bulkSlice(ctx, contents, 2,
+			func(_ int, s string) error {
+				processed.Add(1)
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			},
+			func(_ string, err error) error { return err })
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, int(processed.Load()), len(contents))
+
+		cancelled := 0
+		for _, r := range results {
+			if errors.Is(r, context.DeadlineExceeded) {
+				cancelled++
+			}
+		}
+		require.Equal(t, len(contents)-int(processed.Load()), cancelled)
+	})
+
+	t.Run("BoundedWorkers", func(t *testing.T) {
+		var running, peak atomic.Int32
+		_, err := bulkSlice(context.Background(), contents[:50], 3,
+			func(_ int, s string) int {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return 0
+			},
+			func(string, error) int { return 0 })
+		require.NoError(t, err)
+		require.LessOrEqual(t, int(peak.Load()), 3)
+	})
+
+	t.Run("Seq", func(t *testing.T) {
+		encrypted := make([]string, len(contents))
+		seen := 0
+		for i, r := range BulkEncryptSeq(context.Background(), slices.Values(contents), key, 8) {
+			require.NoError(t, r.Error)
+			require.Equal(t, contents[i], r.Original)
+			encrypted[i] = r.Encrypted
+			seen++
+		}
+		require.Equal(t, len(contents), seen)
+
+		seen = 0
+		for i, r := range BulkDecryptSeq(context.Background(), slices.Values(encrypted), key, 8) {
+			require.NoError(t, r.Error)
+			require.Equal(t, contents[i], r.Decrypted)
+			seen++
+		}
+		require.Equal(t, len(contents), seen)
+	})
+
+	t.Run("SeqEarlyBreak", func(t *testing.T) {
+		seen := 0
+		for range BulkEncryptSeq(context.Background(), slices.Values(contents), key, 4) {
+			seen++
+			if seen == 10 {
+				break
+			}
+		}
+		require.Equal(t, 10, seen)
+	})
+
+	t.Run("SeqCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		seen := 0
+		for range BulkEncryptSeq(ctx, slices.Values(contents), key, 4) {
+			seen++
+			if seen == 10 {
+				cancel()
+			}
+		}
+		require.Less(t, seen, len(contents))
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+
+	t.Run("SeqBlockedSource", func(t *testing.T) {
+		// 첫 항목 뒤로는 release 가 닫힐 때까지 다음 항목을 내놓지 않는 입력
+		release := make(chan struct{})
+		stopped := make(chan bool, 1)
+		source := func(yield func(string) bool) {
+			if !yield("첫번째") {
+				stopped <- true
+				return
+			}
+			<-release
+			stopped <- !yield("두번째")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		finished := make(chan int, 1)
+		go func() {
+			seen := 0
+			for range BulkEncryptSeq(ctx, source, key, 2) {
+				seen++
+				cancel()
+			}
+			finished <- seen
+		}()
+
+		// 입력이 막혀 있어도 취소되면 순회가 끝난다
+		select {
+		case seen := <-finished:
+			require.Equal(t, 1, seen)
+		case <-time.After(time.Second):
+			t.Fatal("입력이 막혀 있는 동안 순회가 끝나지 않았습니다")
+		}
+
+		// 입력이 다음 항목을 내놓으면 생산자는 더 순회하지 않고 끝난다
+		close(release)
+		require.True(t, <-stopped)
+	})
+
+	t.Run("SeqInvalidKey", func(t *testing.T) {
+		for _, r := range BulkDecryptSeq(context.Background(), slices.Values([]string{"a", "b"}), []byte("short"), 2) {
+			require.Error(t, r.Error)
+		}
+	})
+}
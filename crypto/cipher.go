@@ -0,0 +1,277 @@
+package crypto
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// Cipher 키 하나로 만든 재사용 가능한 암호기 (기본 AES-256-GCM)
+//
+// 키 스케줄과 AEAD 를 한 번만 만들어 두고 재사용하므로 대량 처리 시 항목마다 다시 만들 필요가 없다.
+// 암호화는 설정한 알고리즘으로 하고, 복호화는 봉투에 기록된 알고리즘을 자동으로 고른다.
+// 생성할 때 등록된 모든 알고리즘의 AEAD 를 만들어 두고 키 바이트 자체는 따로 보관하지 않는다
+// (생성 이후 RegisterAlgorithm 으로 등록한 알고리즘은 복호화하지 못한다).
+// 여러 고루틴에서 동시에 사용해도 안전하다.
+type Cipher struct {
+	keyID     string
+	algorithm Algorithm
+	aead      cipher.AEAD
+	usage     *UsageTracker
+
+	allowLegacy bool
+	aeads       map[Algorithm]cipher.AEAD // 복호화용 알고리즘별 AEAD (생성 후 읽기 전용)
+}
+
+// CipherConfig Cipher 설정
+type CipherConfig struct {
+	KeyID     string        // 봉투에 기록할 키 ID
+	Algorithm Algorithm     // 암호화 알고리즘 (0 이면 AES-256-GCM, 복호화는 봉투의 알고리즘을 따름)
+	Usage     *UsageTracker // 암호화 횟수 집계, 한도 적용 (nil 이면 집계하지 않음)
+
+	// AllowLegacy aad 를 넘겨도 aad 에 묶이지 않은 기존 형식 암호문을 복호화할지 여부
+	// (끄면 ErrMalformedCiphertext, 기존 값을 옮기는 동안에만 켠다)
+	AllowLegacy bool
+}
+
+// DefaultCipherConfig 기본 Cipher 설정 (키 ID 없음, AES-256-GCM)
+func DefaultCipherConfig() CipherConfig {
+	return CipherConfig{Algorithm: AlgorithmAES256GCM}
+}
+
+// NewCipher 키로 Cipher 생성
+func NewCipher(key []byte) (*Cipher, error) {
+	return NewCipherWithKeyID(key, "")
+}
+
+// NewCipherWithKeyID 암호화할 때 봉투에 키 ID 를 기록하는 Cipher 생성
+func NewCipherWithKeyID(key []byte, keyID string) (*Cipher, error) {
+	config := DefaultCipherConfig()
+	config.KeyID = keyID
+	return NewCipherWithConfig(key, config)
+}
+
+// NewCipherWithConfig 설정으로 Cipher 생성
+func NewCipherWithConfig(key []byte, config CipherConfig) (*Cipher, error) {
+	return newCipher(key, config, true)
+}
+
+// newCipher allAlgorithms 면 복호화용으로 등록된 모든 알고리즘의 AEAD 를 만들고,
+// 아니면 설정한 알고리즘의 AEAD 만 만든다 (값 하나만 처리하는 일회성 패키지 함수용)
+func newCipher(key []byte, config CipherConfig, allAlgorithms bool) (*Cipher, error) {
+	keyID := config.KeyID
+	if len(key) == 0 {
+		return nil, newError(ErrEmptyInput, "key는 비어있을 수 없습니다")
+	}
+
+	// 모든 알고리즘의 키는 32바이트여야 함
+	if err := checkKeySize(key); err != nil {
+		return nil, err
+	}
+
+	if err := checkKeyID(keyID); err != nil {
+		return nil, err
+	}
+
+	if config.Algorithm == 0 {
+		config.Algorithm = AlgorithmAES256GCM
+	}
+	aead, err := newAEAD(config.Algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+
+	aeads := map[Algorithm]cipher.AEAD{config.Algorithm: aead}
+	if allAlgorithms {
+		// 복호화에 쓸 다른 알고리즘의 AEAD (키에 맞지 않는 factory 는 건너뜀)
+		for _, algorithm := range registeredAlgorithms() {
+			if _, ok := aeads[algorithm]; ok {
+				continue
+			}
+			if other, err := newAEAD(algorithm, key); err == nil {
+				aeads[algorithm] = other
+			}
+		}
+	}
+
+	return &Cipher{keyID: keyID, algorithm: config.Algorithm, aead: aead, usage: config.Usage, allowLegacy: config.AllowLegacy, aeads: aeads}, nil
+}
+
+// newOpenCipher 봉투 하나를 복호화하는 데 필요한 AEAD 만 만드는 Cipher (일회성 패키지 함수용)
+//
+// 봉투의 알고리즘을 Cipher 로 사용할 수 없으면 기본 알고리즘으로 만들고, 거부는 open 에 맡긴다.
+func newOpenCipher(key []byte, env *Envelope) (*Cipher, error) {
+	config := DefaultCipherConfig()
+	if checkEnvelopeAlgorithm(env.Algorithm) == nil {
+		config.Algorithm = env.Algorithm
+	}
+	return newCipher(key, config, false)
+}
+
+// withUsage 같은 AEAD 를 공유하고 암호화 횟수만 tracker 로 집계하는 Cipher
+func (c *Cipher) withUsage(tracker *UsageTracker) *Cipher {
+	return &Cipher{keyID: c.keyID, algorithm: c.algorithm, aead: c.aead, usage: tracker, allowLegacy: c.allowLegacy, aeads: c.aeads}
+}
+
+// KeyID 봉투에 기록하는 키 ID
+func (c *Cipher) KeyID() string {
+	return c.keyID
+}
+
+// Algorithm 암호화에 사용하는 알고리즘
+func (c *Cipher) Algorithm() Algorithm {
+	return c.algorithm
+}
+
+// aeadFor 봉투의 알고리즘에 맞는 AEAD
+func (c *Cipher) aeadFor(algorithm Algorithm) (cipher.AEAD, error) {
+	if aead, ok := c.aeads[algorithm]; ok {
+		return aead, nil
+	}
+	return nil, newError(ErrMalformedCiphertext, "지원하지 않는 알고리즘: %s", algorithm)
+}
+
+// Encrypt 문자열 암호화
+func (c *Cipher) Encrypt(content string) (string, error) {
+	return c.EncryptWithAAD(content, nil)
+}
+
+// EncryptWithAAD aad 에 묶어 암호화
+func (c *Cipher) EncryptWithAAD(content string, aad []byte) (string, error) {
+	if len(content) == 0 {
+		return "", newError(ErrEmptyInput, "content는 비어있을 수 없습니다")
+	}
+	return c.seal([]byte(content), aad)
+}
+
+// seal 평문 바이트를 봉투 형식으로 암호화
+func (c *Cipher) seal(plainText []byte, aad []byte) (string, error) {
+	if c.usage != nil {
+		if err := c.usage.reserve(c.keyID, c.algorithm); err != nil {
+			return "", err
+		}
+	}
+
+	env := &Envelope{
+		Version:   EnvelopeVersion1,
+		KeyID:     c.keyID,
+		Algorithm: c.algorithm,
+		Nonce:     make([]byte, c.aead.NonceSize()),
+	}
+	if _, err := io.ReadFull(randReader, env.Nonce); err != nil {
+		return "", fmt.Errorf("nonce 생성 실패: %w", err)
+	}
+
+	// 암호화 실행 (헤더와 aad 를 추가 인증 데이터로 사용)
+	env.Ciphertext = c.aead.Seal(nil, env.Nonce, plainText, append(env.header(), aad...))
+
+	return env.Encode(), nil
+}
+
+// Decrypt 봉투 또는 기존 형식 암호문 복호화
+func (c *Cipher) Decrypt(encryptedText string) (string, error) {
+	return c.DecryptWithAAD(encryptedText, nil)
+}
+
+// DecryptWithAAD 암호화할 때와 같은 aad 로 복호화 (aad 가 있으면 AllowLegacy 가 아닌 한 기존 형식은 거부)
+func (c *Cipher) DecryptWithAAD(encryptedText string, aad []byte) (string, error) {
+	plainText, err := c.decrypt(encryptedText, aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plainText), nil
+}
+
+// decrypt 봉투 또는 기존 형식 암호문을 평문 바이트로 복호화
+func (c *Cipher) decrypt(encryptedText string, aad []byte) ([]byte, error) {
+	if len(encryptedText) == 0 {
+		return nil, newError(ErrEmptyInput, "encryptedText는 비어있을 수 없습니다")
+	}
+
+	if !IsEnvelope(encryptedText) {
+		if len(aad) > 0 && !c.allowLegacy {
+			return nil, legacyAADError()
+		}
+		return c.decryptLegacy(encryptedText)
+	}
+
+	env, err := ParseEnvelope(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	return c.open(env, aad)
+}
+
+// BulkEncrypt 취소 가능한 동시성 제한 대량 암호화
+func (c *Cipher) BulkEncrypt(ctx context.Context, contents []string, concurrencyLimit int) ([]EncryptionResult, error) {
+	return c.bulkEncrypt(ctx, contents, nil, concurrencyLimit)
+}
+
+// BulkDecrypt 취소 가능한 동시성 제한 대량 복호화
+func (c *Cipher) BulkDecrypt(ctx context.Context, encryptedTexts []string, concurrencyLimit int) ([]DecryptionResult, error) {
+	return c.bulkDecrypt(ctx, encryptedTexts, nil, concurrencyLimit)
+}
+
+// open 파싱된 봉투 복호화
+func (c *Cipher) open(env *Envelope, aad []byte) ([]byte, error) {
+	if env.Version == EnvelopeVersion2 {
+		return nil, newError(ErrMalformedCiphertext, "데이터 키 봉투는 DecryptWithKEK 로 복호화해야 합니다")
+	}
+	if env.Version == EnvelopeVersion3 {
+		return nil, newError(ErrMalformedCiphertext, "공개 키 봉투는 OpenWithPrivateKey 로 복호화해야 합니다")
+	}
+	if env.Algorithm == AlgorithmAES256SIV {
+		return nil, newError(ErrMalformedCiphertext, "결정적 암호문은 DecryptDeterministic 으로 복호화해야 합니다")
+	}
+
+	aead, err := c.aeadFor(env.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	// 복호화 실행
+	plainText, err := aead.Open(nil, env.Nonce, env.Ciphertext, append(env.header(), aad...))
+	if err != nil {
+		return nil, wrapError(ErrAuthenticationFailed, err, "복호화 실패 (키가 올바르지 않거나 데이터가 변조됨)")
+	}
+
+	return plainText, nil
+}
+
+// legacyAADError 기존 형식 암호문은 aad 에 묶여 있지 않으므로 aad 를 요구하는 복호화에서 거부한다
+func legacyAADError() error {
+	return newError(ErrMalformedCiphertext, "기존 형식 암호문은 aad 에 묶여 있지 않아 aad 로 복호화할 수 없습니다")
+}
+
+// decryptLegacy 봉투 도입 이전 형식 base64(nonce||ciphertext) 복호화 (항상 AES-256-GCM)
+func (c *Cipher) decryptLegacy(encryptedText string) ([]byte, error) {
+	gcm, err := c.aeadFor(AlgorithmAES256GCM)
+	if err != nil {
+		return nil, err
+	}
+
+	// Base64 디코딩
+	cipherText, err := base64.StdEncoding.DecodeString(encryptedText)
+	if err != nil {
+		return nil, wrapError(ErrMalformedCiphertext, err, "Base64 디코딩 실패")
+	}
+
+	// Nonce 크기 확인
+	nonceSize := gcm.NonceSize()
+	if len(cipherText) < nonceSize {
+		return nil, newError(ErrMalformedCiphertext, "암호화된 텍스트가 너무 짧습니다 (nonce 크기보다 작음)")
+	}
+
+	// Nonce 분리
+	nonce, cipherTextWithoutNonce := cipherText[:nonceSize], cipherText[nonceSize:]
+
+	// 복호화 실행
+	plainText, err := gcm.Open(nil, nonce, cipherTextWithoutNonce, nil)
+	if err != nil {
+		return nil, wrapError(ErrAuthenticationFailed, err, "복호화 실패 (키가 올바르지 않거나 데이터가 변조됨)")
+	}
+
+	return plainText, nil
+}
@@ -0,0 +1,134 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	key := CreateKeyFromString("cipher-test-key")
+
+	t.Run("CompatibleWithFunctions", func(t *testing.T) {
+		c, err := NewCipher(key)
+		require.NoError(t, err)
+
+		encrypted, err := c.Encrypt("값")
+		require.NoError(t, err)
+		decrypted, err := Decrypt(encrypted, key)
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+
+		encrypted, err = Encrypt("값", key)
+		require.NoError(t, err)
+		decrypted, err = c.Decrypt(encrypted)
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+
+		decrypted, err = c.Decrypt(encryptLegacy(t, "기존", key))
+		require.NoError(t, err)
+		require.Equal(t, "기존", decrypted)
+	})
+
+	t.Run("KeyID", func(t *testing.T) {
+		c, err := NewCipherWithKeyID(key, "k9")
+		require.NoError(t, err)
+		require.Equal(t, "k9", c.KeyID())
+
+		encrypted, err := c.EncryptWithAAD("값", NewAAD("row", "1"))
+		require.NoError(t, err)
+		require.Equal(t, "k9", envelopeKeyID(encrypted))
+
+		_, err = c.Decrypt(encrypted)
+		require.Error(t, err)
+		decrypted, err := c.DecryptWithAAD(encrypted, NewAAD("row", "1"))
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		_, err := NewCipher(nil)
+		require.Error(t, err)
+		_, err = NewCipher([]byte("short"))
+		require.Error(t, err)
+	})
+
+	t.Run("ConcurrentUse", func(t *testing.T) {
+		c, err := NewCipher(key)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 50)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				message := fmt.Sprintf("message-%d", i)
+				encrypted, err := c.Encrypt(message)
+				if err != nil {
+					errs <- err
+					return
+				}
+				decrypted, err := c.Decrypt(encrypted)
+				if err != nil || decrypted != message {
+					errs <- fmt.Errorf("round trip %d failed: %v", i, err)
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		c, err := NewCipher(key)
+		require.NoError(t, err)
+
+		contents := []string{"a", "b", ""}
+		encResults, err := c.BulkEncrypt(context.Background(), contents, 2)
+		require.NoError(t, err)
+		require.Error(t, encResults[2].Error)
+
+		decResults, err := c.BulkDecrypt(context.Background(), []string{encResults[0].Encrypted, encResults[1].Encrypted}, 2)
+		require.NoError(t, err)
+		require.Equal(t, "a", decResults[0].Decrypted)
+		require.Equal(t, "b", decResults[1].Decrypted)
+	})
+}
+
+// 벤치마크: 항목마다 AES/GCM 을 새로 만드는 경우 vs Cipher 재사용 (10만 건)
+func BenchmarkCipherReuse100k(b *testing.B) {
+	key := CreateKeyFromString("benchmark-key")
+	data := make([]string, 100000)
+	for i := range data {
+		data[i] = fmt.Sprintf("벤치마크 테스트용 데이터 %d", i)
+	}
+
+	b.Run("PerItemCipher", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = bulkSlice(context.Background(), data, DefaultConcurrencyLimit,
+				func(_ int, content string) EncryptionResult {
+					encrypted, err := Encrypt(content, key)
+					return EncryptionResult{Original: content, Encrypted: encrypted, Error: err}
+				},
+				func(content string, err error) EncryptionResult {
+					return EncryptionResult{Original: content, Error: err}
+				})
+		}
+		b.ReportMetric(float64(len(data)*b.N)/b.Elapsed().Seconds(), "items/s")
+	})
+
+	b.Run("SharedCipher", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			BulkEncryptWithConcurrencyLimit(data, key, DefaultConcurrencyLimit)
+		}
+		b.ReportMetric(float64(len(data)*b.N)/b.Elapsed().Seconds(), "items/s")
+	})
+}
@@ -0,0 +1,158 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+)
+
+// 봉투(envelope) 형식
+//
+//	"cmp:" + base64( version(1) | keyIDLen(1) | keyID | algorithm(1) | nonce | ciphertext )
+//
+// 데이터 키 봉투(version 2)는 algorithm 뒤에 KEK 로 감싼 데이터 키가 붙고, 키 ID 는 KEK ID 가 된다.
+//
+//	"cmp:" + base64( 2 | kekIDLen(1) | kekID | algorithm(1) | wrappedKeyLen(2) | wrappedKey | nonce | ciphertext )
+//
+// 공개 키 봉투(version 3)는 같은 자리에 송신자의 임시 X25519 공개 키가 붙고, 키 ID 는 수신자 키 ID 가 된다 (hybrid.go 참고).
+//
+//	"cmp:" + base64( 3 | recipientIDLen(1) | recipientID | algorithm(1) | ephemeralKeyLen(2) | ephemeralKey | nonce | ciphertext )
+//
+// 결정적 암호문(AES-SIV)은 같은 구조에 "cmpd:" 접두사를 쓰며, nonce 자리에 합성 IV 가 들어간다 (siv.go 참고).
+//
+// nonce 앞까지의 헤더는 GCM 추가 인증 데이터(AAD)로 묶이므로
+// 키 ID 나 알고리즘을 바꿔치기하면 복호화가 실패한다.
+// 접두사가 없는 값은 기존 형식 base64(nonce||ciphertext) 로 간주한다.
+
+// EnvelopePrefix 봉투 형식 암호문의 접두사 (base64 문자셋에 ':' 가 없어 기존 형식과 구분된다)
+const EnvelopePrefix = "cmp:"
+
+const (
+	// EnvelopeVersion1 키로 직접 암호화한 봉투
+	EnvelopeVersion1 byte = 1
+	// EnvelopeVersion2 데이터 키를 KEK 로 감싼 봉투
+	EnvelopeVersion2 byte = 2
+	// EnvelopeVersion3 수신자 공개 키로 암호화한 봉투
+	EnvelopeVersion3 byte = 3
+)
+
+// MaxKeyIDLength 키 ID 최대 길이 (길이 필드가 1바이트)
+const MaxKeyIDLength = 255
+
+// Algorithm 암호문을 만든 알고리즘 식별자
+type Algorithm byte
+
+const (
+	AlgorithmAES256GCM         Algorithm = 1
+	AlgorithmAES256SIV         Algorithm = 2 // 결정적 암호화 (DeterministicCipher)
+	AlgorithmChaCha20Poly1305  Algorithm = 3
+	AlgorithmXChaCha20Poly1305 Algorithm = 4 // 24바이트 nonce
+)
+
+// 알고리즘 이름, nonce 크기, AEAD 생성은 algorithm.go 의 레지스트리에 등록되어 있다
+
+// Envelope 파싱된 봉투 형식 암호문
+type Envelope struct {
+	Version    byte      // 형식 버전
+	KeyID      string    // 암호화에 사용한 키 ID (없으면 빈 문자열)
+	Algorithm  Algorithm // 암호화 알고리즘
+	WrappedKey []byte    // KEK 로 감싼 데이터 키 (version 2), 송신자 임시 공개 키 (version 3)
+	Nonce      []byte    // nonce
+	Ciphertext []byte    // 인증 태그를 포함한 암호문
+}
+
+// header 추가 인증 데이터로 사용되는 헤더 바이트
+func (e *Envelope) header() []byte {
+	h := make([]byte, 0, 3+len(e.KeyID))
+	h = append(h, e.Version, byte(len(e.KeyID)))
+	h = append(h, e.KeyID...)
+	h = append(h, byte(e.Algorithm))
+	if e.Version == EnvelopeVersion2 || e.Version == EnvelopeVersion3 {
+		h = binary.BigEndian.AppendUint16(h, uint16(len(e.WrappedKey)))
+		h = append(h, e.WrappedKey...)
+	}
+	return h
+}
+
+// Encode 봉투를 접두사가 붙은 문자열로 직렬화
+func (e *Envelope) Encode() string {
+	var buf bytes.Buffer
+	buf.Write(e.header())
+	buf.Write(e.Nonce)
+	buf.Write(e.Ciphertext)
+	return e.prefix() + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// prefix 알고리즘에 맞는 접두사
+func (e *Envelope) prefix() string {
+	if e.Algorithm == AlgorithmAES256SIV {
+		return DeterministicPrefix
+	}
+	return EnvelopePrefix
+}
+
+// IsEnvelope 문자열이 봉투 형식인지 여부 (결정적 암호문 포함, 기존 형식이면 false)
+func IsEnvelope(encryptedText string) bool {
+	return strings.HasPrefix(encryptedText, EnvelopePrefix) || IsDeterministic(encryptedText)
+}
+
+// ParseEnvelope 봉투 형식 문자열을 파싱
+func ParseEnvelope(encryptedText string) (*Envelope, error) {
+	if !IsEnvelope(encryptedText) {
+		return nil, newError(ErrMalformedCiphertext, "봉투 형식 암호문이 아닙니다")
+	}
+
+	prefix := EnvelopePrefix
+	if IsDeterministic(encryptedText) {
+		prefix = DeterministicPrefix
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encryptedText, prefix))
+	if err != nil {
+		return nil, wrapError(ErrMalformedCiphertext, err, "Base64 디코딩 실패")
+	}
+
+	if len(raw) < 3 {
+		return nil, newError(ErrMalformedCiphertext, "봉투 헤더가 너무 짧습니다")
+	}
+
+	env := &Envelope{Version: raw[0]}
+	if env.Version < EnvelopeVersion1 || env.Version > EnvelopeVersion3 {
+		return nil, newError(ErrMalformedCiphertext, "지원하지 않는 봉투 버전: %d", env.Version)
+	}
+
+	keyIDLen := int(raw[1])
+	if len(raw) < 3+keyIDLen {
+		return nil, newError(ErrMalformedCiphertext, "봉투 헤더가 너무 짧습니다 (키 ID 길이 불일치)")
+	}
+	env.KeyID = string(raw[2 : 2+keyIDLen])
+	env.Algorithm = Algorithm(raw[2+keyIDLen])
+
+	nonceSize := env.Algorithm.NonceSize()
+	if nonceSize == 0 {
+		return nil, newError(ErrMalformedCiphertext, "지원하지 않는 알고리즘: %s", env.Algorithm)
+	}
+	if env.prefix() != prefix || (env.Algorithm == AlgorithmAES256SIV && env.Version != EnvelopeVersion1) {
+		return nil, newError(ErrMalformedCiphertext, "접두사 %q 와 알고리즘 %s 가 맞지 않습니다", prefix, env.Algorithm)
+	}
+
+	body := raw[3+keyIDLen:]
+	if env.Version == EnvelopeVersion2 || env.Version == EnvelopeVersion3 {
+		if len(body) < 2 {
+			return nil, newError(ErrMalformedCiphertext, "봉투 헤더가 너무 짧습니다 (데이터 키 길이 없음)")
+		}
+		wrappedLen := int(binary.BigEndian.Uint16(body))
+		if len(body) < 2+wrappedLen {
+			return nil, newError(ErrMalformedCiphertext, "봉투 헤더가 너무 짧습니다 (데이터 키 길이 불일치)")
+		}
+		env.WrappedKey, body = body[2:2+wrappedLen], body[2+wrappedLen:]
+	}
+
+	if len(body) < nonceSize {
+		return nil, newError(ErrMalformedCiphertext, "암호화된 텍스트가 너무 짧습니다 (nonce 크기보다 작음)")
+	}
+	env.Nonce, env.Ciphertext = body[:nonceSize], body[nonceSize:]
+
+	return env, nil
+}
@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// 봉투 도입 이전 형식으로 암호화 (호환성 확인용)
+func encryptLegacy(t *testing.T, content string, key []byte) string {
+	gcm, err := newGCM(key)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(content), nil))
+}
+
+func TestEnvelope(t *testing.T) {
+	key := CreateKeyFromString("envelope-test-key")
+	message := "프로바이더 자격증명"
+
+	t.Run("EncryptWithKeyID", func(t *testing.T) {
+		encrypted, err := EncryptWithKeyID(message, key, "key-2025")
+		require.NoError(t, err)
+		require.True(t, IsEnvelope(encrypted))
+
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		require.Equal(t, EnvelopeVersion1, env.Version)
+		require.Equal(t, "key-2025", env.KeyID)
+		require.Equal(t, AlgorithmAES256GCM, env.Algorithm)
+		require.Len(t, env.Nonce, AlgorithmAES256GCM.NonceSize())
+		require.Equal(t, encrypted, env.Encode())
+
+		decrypted, err := Decrypt(encrypted, key)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+	})
+
+	t.Run("LegacyFormat", func(t *testing.T) {
+		legacy := encryptLegacy(t, message, key)
+		require.False(t, IsEnvelope(legacy))
+
+		decrypted, err := Decrypt(legacy, key)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+	})
+
+	t.Run("KeyLookup", func(t *testing.T) {
+		oldKey := CreateKeyFromString("old-key")
+		keys := map[string][]byte{"": oldKey, "new": key}
+		lookup := func(keyID string) ([]byte, error) {
+			k, ok := keys[keyID]
+			if !ok {
+				return nil, fmt.Errorf("unknown key id %q", keyID)
+			}
+			return k, nil
+		}
+
+		encrypted, err := EncryptWithKeyID(message, key, "new")
+		require.NoError(t, err)
+		decrypted, err := DecryptWithKeyLookup(encrypted, lookup)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+
+		decrypted, err = DecryptWithKeyLookup(encryptLegacy(t, message, oldKey), lookup)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+
+		encrypted, err = EncryptWithKeyID(message, key, "missing")
+		require.NoError(t, err)
+		_, err = DecryptWithKeyLookup(encrypted, lookup)
+		require.Error(t, err)
+	})
+
+	t.Run("TamperedHeader", func(t *testing.T) {
+		encrypted, err := EncryptWithKeyID(message, key, "a")
+		require.NoError(t, err)
+
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		env.KeyID = "b"
+
+		_, err = Decrypt(env.Encode(), key)
+		require.Error(t, err)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, s := range []string{
+			EnvelopePrefix,
+			EnvelopePrefix + "!!!",
+			EnvelopePrefix + base64.StdEncoding.EncodeToString([]byte{9, 0, 1}),
+			EnvelopePrefix + base64.StdEncoding.EncodeToString([]byte{1, 10, 'a'}),
+			EnvelopePrefix + base64.StdEncoding.EncodeToString([]byte{1, 0, 99}),
+			EnvelopePrefix + base64.StdEncoding.EncodeToString([]byte{1, 0, 1, 0, 0}),
+		} {
+			_, err := Decrypt(s, key)
+			require.Error(t, err, s)
+		}
+	})
+
+	t.Run("KeyIDTooLong", func(t *testing.T) {
+		_, err := EncryptWithKeyID(message, key, strings.Repeat("k", MaxKeyIDLength+1))
+		require.Error(t, err)
+	})
+}
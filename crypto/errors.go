@@ -0,0 +1,134 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 오류 분류
+//
+// crypto 패키지의 오류는 아래 sentinel 중 하나로 분류되어 errors.Is 로 구분할 수 있다.
+// 대량 처리 결과(EncryptionResult, DecryptionResult 등)의 Error 도 같은 방식으로 확인한다.
+//
+//	switch {
+//	case errors.Is(err, crypto.ErrAuthenticationFailed), errors.Is(err, crypto.ErrMalformedCiphertext):
+//		// 변조되었거나 잘못된 입력 (4xx)
+//	case errors.Is(err, crypto.ErrInvalidKeySize), errors.Is(err, crypto.ErrUnknownKeyID), errors.Is(err, crypto.ErrInvalidConfig):
+//		// 키 설정 오류 (5xx)
+//	case errors.Is(err, crypto.ErrKeyUsageExceeded):
+//		// 키 교체 필요 (5xx)
+//	}
+//
+// 상세 정보가 필요하면 errors.As 로 *KeySizeError, *KeyIDError, *Error 를 꺼낸다.
+
+var (
+	// ErrInvalidKeySize 키 길이가 올바르지 않음
+	ErrInvalidKeySize = errors.New("키 길이가 올바르지 않습니다")
+	// ErrEmptyInput 키, 평문, 암호문 등 필수 입력이 비어있음
+	ErrEmptyInput = errors.New("입력이 비어있습니다")
+	// ErrMalformedCiphertext 암호문 형식이 올바르지 않음 (base64, 헤더, 길이, 잘림 등)
+	ErrMalformedCiphertext = errors.New("암호문 형식이 올바르지 않습니다")
+	// ErrAuthenticationFailed 인증 실패 (키가 올바르지 않거나 데이터가 변조됨)
+	ErrAuthenticationFailed = errors.New("복호화 실패 (키가 올바르지 않거나 데이터가 변조됨)")
+	// ErrUnknownKeyID 암호문에 기록된 키 ID 의 키를 찾을 수 없음
+	ErrUnknownKeyID = errors.New("알 수 없는 키 ID")
+	// ErrInvalidConfig 키 ID, 알고리즘 등 설정 값이 올바르지 않음
+	ErrInvalidConfig = errors.New("설정이 올바르지 않습니다")
+	// ErrKeyUsageExceeded 키의 암호화 횟수가 한도에 도달해 암호화를 거부함 (usage.go)
+	ErrKeyUsageExceeded = errors.New("키 사용 한도 초과")
+)
+
+// ErrorKind 오류가 속한 분류 sentinel, 분류되지 않은 오류면 nil
+func ErrorKind(err error) error {
+	for _, kind := range []error{ErrInvalidKeySize, ErrEmptyInput, ErrMalformedCiphertext, ErrAuthenticationFailed, ErrUnknownKeyID, ErrInvalidConfig, ErrKeyUsageExceeded} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// Error 분류(Kind)와 상세 메시지를 가진 오류, errors.Is(err, Kind) 와 원인 오류 확인이 모두 가능하다
+type Error struct {
+	Kind error  // 분류 sentinel
+	Msg  string // 상세 메시지
+	Err  error  // 원인 오류 (없을 수 있음)
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Msg
+	}
+	return e.Msg + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// newError 분류된 오류 생성
+func newError(kind error, format string, args ...any) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+// wrapError 원인 오류를 감싼 분류된 오류 생성
+func wrapError(kind error, err error, format string, args ...any) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...), Err: err}
+}
+
+// KeySizeError 키 길이 오류, errors.Is(err, ErrInvalidKeySize) 가 참
+type KeySizeError struct {
+	Want    int  // 필요한 길이 (바이트)
+	Got     int  // 실제 길이 (바이트)
+	AtLeast bool // Want 이상이면 되는 경우
+}
+
+func (e *KeySizeError) Error() string {
+	if e.AtLeast {
+		return fmt.Sprintf("키는 %d바이트 이상이어야 함: 현재 %d 바이트", e.Want, e.Got)
+	}
+	return fmt.Sprintf("키 길이가 %d바이트여야 함: 현재 %d 바이트", e.Want, e.Got)
+}
+
+func (e *KeySizeError) Unwrap() error {
+	return ErrInvalidKeySize
+}
+
+// KeyIDError 키 ID 에 해당하는 키가 없음, errors.Is(err, ErrUnknownKeyID) 가 참
+type KeyIDError struct {
+	KeyID string // 찾지 못한 키 ID
+	Err   error  // 원인 오류 (없을 수 있음)
+}
+
+func (e *KeyIDError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("등록되지 않은 키 ID: %s", e.KeyID)
+	}
+	return fmt.Sprintf("등록되지 않은 키 ID: %s: %s", e.KeyID, e.Err)
+}
+
+func (e *KeyIDError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrUnknownKeyID}
+	}
+	return []error{ErrUnknownKeyID, e.Err}
+}
+
+// checkKeySize 키 길이 확인
+func checkKeySize(key []byte) error {
+	if len(key) != KeySize {
+		return &KeySizeError{Want: KeySize, Got: len(key)}
+	}
+	return nil
+}
+
+// checkKeyID 봉투에 기록할 수 있는 키 ID 인지 확인
+func checkKeyID(keyID string) error {
+	if len(keyID) > MaxKeyIDLength {
+		return newError(ErrInvalidConfig, "키 ID 는 %d바이트를 넘을 수 없습니다: 현재 %d 바이트", MaxKeyIDLength, len(keyID))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorTaxonomy(t *testing.T) {
+	key := CreateKeyFromString("errors-test-key")
+	encrypted, err := Encrypt("값", key)
+	require.NoError(t, err)
+
+	t.Run("InvalidKeySize", func(t *testing.T) {
+		_, err := Encrypt("값", []byte("short"))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+
+		var sizeErr *KeySizeError
+		require.ErrorAs(t, err, &sizeErr)
+		require.Equal(t, KeySize, sizeErr.Want)
+		require.Equal(t, 5, sizeErr.Got)
+
+		_, err = NewDeterministicCipher(make([]byte, 16))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+		_, err = NewEncryptWriter(&bytes.Buffer{}, make([]byte, 16), DefaultStreamConfig())
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+		_, err = NewBlindIndexer(make([]byte, 16), DefaultBlindIndexConfig())
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+	})
+
+	t.Run("EmptyInput", func(t *testing.T) {
+		_, err := Encrypt("", key)
+		require.ErrorIs(t, err, ErrEmptyInput)
+		_, err = Decrypt("", key)
+		require.ErrorIs(t, err, ErrEmptyInput)
+		_, err = NewCipher(nil)
+		require.ErrorIs(t, err, ErrEmptyInput)
+	})
+
+	t.Run("MalformedCiphertext", func(t *testing.T) {
+		for _, text := range []string{"not base64!", "c2hvcnQ=", EnvelopePrefix + "!!!", EnvelopePrefix + "AQ==", EnvelopePrefix + "CQAB"} {
+			_, err := Decrypt(text, key)
+			require.ErrorIs(t, err, ErrMalformedCiphertext, text)
+			require.NotErrorIs(t, err, ErrAuthenticationFailed, text)
+		}
+
+		deterministic, err := EncryptDeterministic("값", key)
+		require.NoError(t, err)
+		_, err = Decrypt(deterministic, key)
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+
+		_, err = NewDecryptReader(strings.NewReader("CMP"), key)
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		longID := strings.Repeat("k", MaxKeyIDLength+1)
+
+		_, err := EncryptWithKeyID("값", key, longID)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		require.Equal(t, ErrInvalidConfig, ErrorKind(err))
+		_, err = NewDeterministicCipherWithKeyID(key, longID)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		_, err = NewEncryptWriter(&bytes.Buffer{}, key, StreamConfig{ChunkSize: 1024, KeyID: longID})
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		_, err = NewHybridPublicKey(longID, make([]byte, 32))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+
+		kek, err := NewLocalKEK(longID, key)
+		require.NoError(t, err)
+		_, err = EncryptWithKEK(context.Background(), "값", kek, nil)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("AuthenticationFailed", func(t *testing.T) {
+		_, err := Decrypt(encrypted, CreateKeyFromString("other-key"))
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+
+		var cryptoErr *Error
+		require.ErrorAs(t, err, &cryptoErr)
+		require.Equal(t, ErrAuthenticationFailed, cryptoErr.Kind)
+		require.Equal(t, ErrAuthenticationFailed, ErrorKind(err))
+
+		_, err = DecryptWithAAD(encrypted, key, []byte("wrong-aad"))
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+
+		_, err = DecryptDeterministic(mustEncryptDeterministic(t, key), CreateKeyFromString("other-key"))
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+	})
+
+	t.Run("UnknownKeyID", func(t *testing.T) {
+		keyring, err := NewKeyring("k1", key)
+		require.NoError(t, err)
+
+		other, err := EncryptWithKeyID("값", CreateKeyFromString("other-key"), "k2")
+		require.NoError(t, err)
+
+		_, err = keyring.Decrypt(other)
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+		var keyIDErr *KeyIDError
+		require.ErrorAs(t, err, &keyIDErr)
+		require.Equal(t, "k2", keyIDErr.KeyID)
+
+		// 등록된 키로 암호화했지만 변조된 경우는 인증 실패
+		tampered, err := keyring.Encrypt("값")
+		require.NoError(t, err)
+		_, err = keyring.DecryptWithAAD(tampered, []byte("aad"))
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+		require.NotErrorIs(t, err, ErrUnknownKeyID)
+
+		require.ErrorIs(t, keyring.SetPrimary("missing"), ErrUnknownKeyID)
+
+		_, err = DecryptWithKeyLookup(other, func(keyID string) ([]byte, error) {
+			return nil, errors.New("not found")
+		})
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+
+		// 기존 형식 값은 빈 키 ID 로 조회하고, 실패하면 같은 KeyIDError
+		_, err = DecryptWithKeyLookup(encryptLegacy(t, "값", key), func(keyID string) ([]byte, error) {
+			return nil, errors.New("not found")
+		})
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+		keyIDErr = nil
+		require.ErrorAs(t, err, &keyIDErr)
+		require.Empty(t, keyIDErr.KeyID)
+	})
+
+	t.Run("BulkResults", func(t *testing.T) {
+		results := BulkDecrypt([]string{encrypted, "not base64!"}, key)
+		require.NoError(t, results[0].Error)
+		require.ErrorIs(t, results[1].Error, ErrMalformedCiphertext)
+		require.Equal(t, ErrMalformedCiphertext, ErrorKind(results[1].Error))
+
+		keyring, err := NewKeyring("k1", key)
+		require.NoError(t, err)
+		other, err := EncryptWithKeyID("값", CreateKeyFromString("other-key"), "k2")
+		require.NoError(t, err)
+		keyringResults := keyring.BulkDecrypt([]string{encrypted, other}, 2)
+		require.NoError(t, keyringResults[0].Error)
+		require.ErrorIs(t, keyringResults[1].Error, ErrUnknownKeyID)
+
+		encResults := BulkEncrypt([]string{"값", ""}, key)
+		require.NoError(t, encResults[0].Error)
+		require.ErrorIs(t, encResults[1].Error, ErrEmptyInput)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cancelled, err := BulkDecryptContext(ctx, []string{encrypted}, key, 1)
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, cancelled[0].Error, context.Canceled)
+		require.Nil(t, ErrorKind(cancelled[0].Error))
+	})
+}
+
+func mustEncryptDeterministic(t *testing.T, key []byte) string {
+	t.Helper()
+	encrypted, err := EncryptDeterministic("값", key)
+	require.NoError(t, err)
+	return encrypted
+}
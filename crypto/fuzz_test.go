@@ -0,0 +1,129 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// 퍼징 대상
+//
+//	go test ./crypto -run '^$' -fuzz FuzzDecrypt -fuzztime 1m
+//
+// 잘못된 입력에 패닉 없이 오류를 반환하는지, 그 오류가 ErrorKind 로 분류되는지,
+// 변조되거나 잘린 암호문이 복호화되지 않는지 확인한다.
+
+var fuzzKey = CreateKeyFromString("fuzz-key")
+
+// fuzzSeeds 정상 암호문과 흔한 손상 형태
+func fuzzSeeds(f *testing.F) []string {
+	f.Helper()
+	var seeds []string
+	for _, algorithm := range []Algorithm{AlgorithmAES256GCM, AlgorithmChaCha20Poly1305, AlgorithmXChaCha20Poly1305} {
+		encrypted, err := EncryptWithAlgorithm("fuzz seed 값", fuzzKey, algorithm)
+		require.NoError(f, err)
+		seeds = append(seeds, encrypted, encrypted[:len(encrypted)/2], encrypted[:len(encrypted)-1])
+	}
+	deterministic, err := EncryptDeterministic("fuzz", fuzzKey)
+	require.NoError(f, err)
+	legacy := base64.StdEncoding.EncodeToString(make([]byte, 40))
+
+	return append(seeds, deterministic, legacy,
+		"", "cmp:", "cmpd:", "cmp:AQ==", "cmp:AQAB", "cmp:AgABAAA=", "cmp:!!!!", "not base64 at all", "====")
+}
+
+func FuzzDecrypt(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, encryptedText string) {
+		_, err := Decrypt(encryptedText, fuzzKey)
+		if err != nil {
+			require.NotNil(t, ErrorKind(err), "분류되지 않은 오류: %v", err)
+		}
+	})
+}
+
+func FuzzDecryptTampered(f *testing.F) {
+	f.Add(0, byte(0x01), 0)
+	f.Add(2, byte(0x80), 0)
+	f.Add(0, byte(0), 1)
+	f.Add(10, byte(0xff), 5)
+
+	encrypted, err := EncryptWithKeyID("변조 확인용 값", fuzzKey, "fuzz")
+	require.NoError(f, err)
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, EnvelopePrefix))
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, position int, mask byte, truncate int) {
+		tampered := bytes.Clone(raw)
+		if position >= 0 && position < len(tampered) {
+			tampered[position] ^= mask
+		}
+		if truncate > 0 && truncate <= len(tampered) {
+			tampered = tampered[:len(tampered)-truncate]
+		}
+		if bytes.Equal(tampered, raw) {
+			return
+		}
+
+		_, err := Decrypt(EnvelopePrefix+base64.StdEncoding.EncodeToString(tampered), fuzzKey)
+		require.Error(t, err)
+		require.NotNil(t, ErrorKind(err), "분류되지 않은 오류: %v", err)
+	})
+}
+
+func FuzzParseEnvelope(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, encryptedText string) {
+		env, err := ParseEnvelope(encryptedText)
+		if err != nil {
+			require.ErrorIs(t, err, ErrMalformedCiphertext)
+			return
+		}
+
+		// 파싱에 성공하면 다시 인코딩해도 같은 봉투
+		again, err := ParseEnvelope(env.Encode())
+		require.NoError(t, err)
+		require.Equal(t, env, again)
+	})
+}
+
+func FuzzDecryptReader(f *testing.F) {
+	plainText := []byte("청크 여러 개로 나뉘는 스트림 퍼징 시드")
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, fuzzKey, StreamConfig{ChunkSize: 16})
+	require.NoError(f, err)
+	_, err = w.Write(plainText)
+	require.NoError(f, err)
+	require.NoError(f, w.Close())
+
+	stream := buf.Bytes()
+	f.Add(stream)
+	f.Add(stream[:len(stream)/2])
+	f.Add(stream[:20])
+	f.Add([]byte("CMPS"))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, err := NewDecryptReader(bytes.NewReader(data), fuzzKey)
+		var decrypted []byte
+		if err == nil {
+			decrypted, err = io.ReadAll(r)
+		}
+		if err != nil {
+			require.NotNil(t, ErrorKind(err), "분류되지 않은 오류: %v", err)
+			return
+		}
+		// 청크는 순서와 마지막 표시까지 인증되므로 복호화에 성공했다면 시드의 평문이어야 한다
+		require.Equal(t, plainText, decrypted, "변조된 스트림이 복호화됨")
+	})
+}
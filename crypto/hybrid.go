@@ -0,0 +1,212 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// 공개 키 암호화 (X25519 + HKDF-SHA256 + AEAD, HPKE base 모드와 같은 구성)
+//
+// 고객사에서 동작하는 수집기가 대칭 키를 공유하지 않고 중앙 CMP 의 공개 키만으로 수집한 자격 증명을 암호화할 때 사용한다.
+// 암호화할 때마다 임시 X25519 키 쌍을 만들어 수신자 공개 키와 공유 비밀을 계산하고,
+// 공유 비밀에서 HKDF-SHA256 으로 AEAD 키를 유도한다. salt 는 임시 공개 키와 수신자 공개 키,
+// info 는 도메인 구분자와 알고리즘이므로 다른 수신자나 알고리즘으로 바꿔 풀 수 없다.
+//
+//	"cmp:" + base64( 3 | recipientIDLen(1) | recipientID | algorithm(1) | ephemeralKeyLen(2) | ephemeralKey(32) | nonce | ciphertext )
+//
+// 헤더와 aad 는 AEAD 의 추가 인증 데이터로 들어간다. 개인 키는 복호화하는 쪽에만 두고, 수집기에는 공개 키만 배포한다.
+
+// HybridKeySize X25519 공개 키, 개인 키 크기
+const HybridKeySize = 32
+
+// hybridKeyInfo 공개 키 암호화의 HKDF info 도메인 구분자
+const hybridKeyInfo = "cmp-common crypto hybrid-v1"
+
+// HybridPublicKey 수신자 X25519 공개 키, 수집기에 배포한다
+type HybridPublicKey struct {
+	keyID string
+	key   *ecdh.PublicKey
+}
+
+// NewHybridPublicKey 32바이트 X25519 공개 키로 HybridPublicKey 생성
+func NewHybridPublicKey(keyID string, publicKey []byte) (*HybridPublicKey, error) {
+	if err := checkKeyID(keyID); err != nil {
+		return nil, err
+	}
+	if len(publicKey) != HybridKeySize {
+		return nil, &KeySizeError{Want: HybridKeySize, Got: len(publicKey)}
+	}
+	key, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("X25519 공개 키 생성 실패: %w", err)
+	}
+	return &HybridPublicKey{keyID: keyID, key: key}, nil
+}
+
+// KeyID 암호문에 기록되는 수신자 키 ID
+func (k *HybridPublicKey) KeyID() string {
+	return k.keyID
+}
+
+// Bytes 배포용 공개 키 바이트
+func (k *HybridPublicKey) Bytes() []byte {
+	return k.key.Bytes()
+}
+
+// HybridPrivateKey 수신자 X25519 개인 키, 복호화하는 쪽에만 둔다
+type HybridPrivateKey struct {
+	keyID string
+	key   *ecdh.PrivateKey
+}
+
+// NewHybridPrivateKey 32바이트 X25519 개인 키로 HybridPrivateKey 생성
+func NewHybridPrivateKey(keyID string, privateKey []byte) (*HybridPrivateKey, error) {
+	if err := checkKeyID(keyID); err != nil {
+		return nil, err
+	}
+	if len(privateKey) != HybridKeySize {
+		return nil, &KeySizeError{Want: HybridKeySize, Got: len(privateKey)}
+	}
+	key, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("X25519 개인 키 생성 실패: %w", err)
+	}
+	return &HybridPrivateKey{keyID: keyID, key: key}, nil
+}
+
+// GenerateHybridKey 새 X25519 키 쌍으로 HybridPrivateKey 생성
+func GenerateHybridKey(keyID string) (*HybridPrivateKey, error) {
+	if err := checkKeyID(keyID); err != nil {
+		return nil, err
+	}
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("X25519 키 생성 실패: %w", err)
+	}
+	return &HybridPrivateKey{keyID: keyID, key: key}, nil
+}
+
+// KeyID 복호화할 암호문의 수신자 키 ID
+func (k *HybridPrivateKey) KeyID() string {
+	return k.keyID
+}
+
+// PublicKey 수집기에 배포할 공개 키
+func (k *HybridPrivateKey) PublicKey() *HybridPublicKey {
+	return &HybridPublicKey{keyID: k.keyID, key: k.key.PublicKey()}
+}
+
+// Bytes 보관용 개인 키 바이트, 사용 후 Destroy 로 지운다
+func (k *HybridPrivateKey) Bytes() *SecretBytes {
+	return NewSecretBytes(k.key.Bytes())
+}
+
+// SealWithPublicKey 수신자 공개 키로 암호화 (algorithm 은 AES-256-GCM, ChaCha20-Poly1305 등 등록된 AEAD 알고리즘)
+func SealWithPublicKey(content string, recipient *HybridPublicKey, algorithm Algorithm, aad []byte) (string, error) {
+	if len(content) == 0 || recipient == nil {
+		return "", newError(ErrEmptyInput, "content와 recipient는 비어있을 수 없습니다")
+	}
+
+	// X25519 는 32바이트 아무 값이나 개인 키가 되므로 nonce 와 같은 난수원에서 읽는다
+	seed := make([]byte, HybridKeySize)
+	if _, err := io.ReadFull(randReader, seed); err != nil {
+		return "", fmt.Errorf("임시 X25519 키 생성 실패: %w", err)
+	}
+	defer clear(seed)
+	ephemeral, err := ecdh.X25519().NewPrivateKey(seed)
+	if err != nil {
+		return "", fmt.Errorf("임시 X25519 키 생성 실패: %w", err)
+	}
+	shared, err := ephemeral.ECDH(recipient.key)
+	if err != nil {
+		return "", fmt.Errorf("X25519 키 합의 실패: %w", err)
+	}
+	defer clear(shared)
+
+	ephemeralKey := ephemeral.PublicKey().Bytes()
+	aead, err := newHybridAEAD(shared, ephemeralKey, recipient.key.Bytes(), algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	env := &Envelope{
+		Version:    EnvelopeVersion3,
+		KeyID:      recipient.keyID,
+		Algorithm:  algorithm,
+		WrappedKey: ephemeralKey,
+		Nonce:      make([]byte, aead.NonceSize()),
+	}
+	if _, err = io.ReadFull(randReader, env.Nonce); err != nil {
+		return "", fmt.Errorf("nonce 생성 실패: %w", err)
+	}
+
+	env.Ciphertext = aead.Seal(nil, env.Nonce, []byte(content), append(env.header(), aad...))
+
+	return env.Encode(), nil
+}
+
+// OpenWithPrivateKey 수신자 개인 키로 SealWithPublicKey 암호문 복호화
+func OpenWithPrivateKey(encryptedText string, recipient *HybridPrivateKey, aad []byte) (string, error) {
+	if len(encryptedText) == 0 || recipient == nil {
+		return "", newError(ErrEmptyInput, "encryptedText와 recipient는 비어있을 수 없습니다")
+	}
+
+	env, err := ParseEnvelope(encryptedText)
+	if err != nil {
+		return "", err
+	}
+	if env.Version != EnvelopeVersion3 {
+		return "", newError(ErrMalformedCiphertext, "공개 키 봉투가 아닙니다")
+	}
+	if env.KeyID != recipient.keyID {
+		return "", &KeyIDError{KeyID: env.KeyID, Err: errors.New("다른 수신자 공개 키로 암호화된 값입니다")}
+	}
+	if len(env.WrappedKey) != HybridKeySize {
+		return "", newError(ErrMalformedCiphertext, "임시 공개 키 크기가 올바르지 않습니다: %d 바이트", len(env.WrappedKey))
+	}
+
+	if err := checkEnvelopeAlgorithm(env.Algorithm); err != nil {
+		return "", err
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(env.WrappedKey)
+	if err != nil {
+		return "", wrapError(ErrMalformedCiphertext, err, "임시 공개 키 형식 오류")
+	}
+	shared, err := recipient.key.ECDH(ephemeral)
+	if err != nil {
+		return "", wrapError(ErrMalformedCiphertext, err, "X25519 키 합의 실패")
+	}
+	defer clear(shared)
+
+	aead, err := newHybridAEAD(shared, env.WrappedKey, recipient.key.PublicKey().Bytes(), env.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	plainText, err := aead.Open(nil, env.Nonce, env.Ciphertext, append(env.header(), aad...))
+	if err != nil {
+		return "", wrapError(ErrAuthenticationFailed, err, "복호화 실패 (키가 올바르지 않거나 데이터가 변조됨)")
+	}
+
+	return string(plainText), nil
+}
+
+// newHybridAEAD 공유 비밀에서 AEAD 키를 유도
+func newHybridAEAD(shared, ephemeralKey, recipientKey []byte, algorithm Algorithm) (cipher.AEAD, error) {
+	salt := bytes.Join([][]byte{ephemeralKey, recipientKey}, nil)
+	key, err := hkdf.Key(sha256.New, shared, salt, hybridKeyInfo+" "+algorithm.String(), KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("공개 키 암호화 키 유도 실패: %w", err)
+	}
+	defer clear(key)
+
+	return newAEAD(algorithm, key)
+}
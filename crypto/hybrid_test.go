@@ -0,0 +1,124 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHybridEncryption(t *testing.T) {
+	core, err := GenerateHybridKey("cmp-core-1")
+	require.NoError(t, err)
+
+	// 수집기는 배포받은 공개 키 바이트만 가진다
+	published, err := NewHybridPublicKey(core.KeyID(), core.PublicKey().Bytes())
+	require.NoError(t, err)
+
+	t.Run("SealOpen", func(t *testing.T) {
+		for _, algorithm := range []Algorithm{AlgorithmAES256GCM, AlgorithmChaCha20Poly1305} {
+			t.Run(algorithm.String(), func(t *testing.T) {
+				encrypted, err := SealWithPublicKey("db-password", published, algorithm, []byte("collector-7"))
+				require.NoError(t, err)
+				require.True(t, IsEnvelope(encrypted))
+
+				env, err := ParseEnvelope(encrypted)
+				require.NoError(t, err)
+				require.Equal(t, EnvelopeVersion3, env.Version)
+				require.Equal(t, "cmp-core-1", env.KeyID)
+				require.Equal(t, algorithm, env.Algorithm)
+				require.Len(t, env.WrappedKey, HybridKeySize)
+
+				decrypted, err := OpenWithPrivateKey(encrypted, core, []byte("collector-7"))
+				require.NoError(t, err)
+				require.Equal(t, "db-password", decrypted)
+
+				_, err = OpenWithPrivateKey(encrypted, core, []byte("collector-8"))
+				require.ErrorIs(t, err, ErrAuthenticationFailed)
+			})
+		}
+	})
+
+	t.Run("EphemeralKeyPerMessage", func(t *testing.T) {
+		a, err := SealWithPublicKey("같은 값", published, AlgorithmAES256GCM, nil)
+		require.NoError(t, err)
+		b, err := SealWithPublicKey("같은 값", published, AlgorithmAES256GCM, nil)
+		require.NoError(t, err)
+
+		envA, err := ParseEnvelope(a)
+		require.NoError(t, err)
+		envB, err := ParseEnvelope(b)
+		require.NoError(t, err)
+		require.NotEqual(t, envA.WrappedKey, envB.WrappedKey)
+	})
+
+	t.Run("PrivateKeyRoundTrip", func(t *testing.T) {
+		raw := core.Bytes()
+		defer raw.Destroy()
+		restored, err := NewHybridPrivateKey(core.KeyID(), raw.Bytes())
+		require.NoError(t, err)
+
+		encrypted, err := SealWithPublicKey("값", published, AlgorithmChaCha20Poly1305, nil)
+		require.NoError(t, err)
+		decrypted, err := OpenWithPrivateKey(encrypted, restored, nil)
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+	})
+
+	t.Run("WrongRecipient", func(t *testing.T) {
+		encrypted, err := SealWithPublicKey("값", published, AlgorithmAES256GCM, nil)
+		require.NoError(t, err)
+
+		other, err := GenerateHybridKey("cmp-core-2")
+		require.NoError(t, err)
+		_, err = OpenWithPrivateKey(encrypted, other, nil)
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+
+		// 키 ID 가 같아도 다른 개인 키로는 풀 수 없음
+		impostor, err := GenerateHybridKey("cmp-core-1")
+		require.NoError(t, err)
+		_, err = OpenWithPrivateKey(encrypted, impostor, nil)
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		encrypted, err := SealWithPublicKey("값", published, AlgorithmAES256GCM, nil)
+		require.NoError(t, err)
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+
+		// 알고리즘을 바꿔 붙이면 키 유도와 헤더가 달라져 실패
+		env.Algorithm = AlgorithmChaCha20Poly1305
+		_, err = OpenWithPrivateKey(env.Encode(), core, nil)
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+
+		env.Algorithm = AlgorithmAES256GCM
+		env.WrappedKey[0] ^= 0x01
+		_, err = OpenWithPrivateKey(env.Encode(), core, nil)
+		require.ErrorIs(t, err, ErrAuthenticationFailed)
+	})
+
+	t.Run("NotForSymmetricAPI", func(t *testing.T) {
+		encrypted, err := SealWithPublicKey("값", published, AlgorithmAES256GCM, nil)
+		require.NoError(t, err)
+		_, err = Decrypt(encrypted, CreateKeyFromString("key"))
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+
+		symmetric, err := Encrypt("값", CreateKeyFromString("key"))
+		require.NoError(t, err)
+		_, err = OpenWithPrivateKey(symmetric, core, nil)
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		_, err := SealWithPublicKey("", published, AlgorithmAES256GCM, nil)
+		require.ErrorIs(t, err, ErrEmptyInput)
+		_, err = SealWithPublicKey("값", nil, AlgorithmAES256GCM, nil)
+		require.ErrorIs(t, err, ErrEmptyInput)
+		_, err = SealWithPublicKey("값", published, AlgorithmAES256SIV, nil)
+		require.Error(t, err)
+		_, err = NewHybridPublicKey("k", make([]byte, 16))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+		_, err = NewHybridPrivateKey("k", make([]byte, 16))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+	})
+}
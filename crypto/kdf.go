@@ -0,0 +1,281 @@
+package crypto
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// 패스프레이즈 기반 키 유도 (KDF)
+//
+// 유도된 키와 함께 PHC 형식의 파라미터 문자열을 저장해 두면 같은 키를 다시 유도할 수 있다.
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>
+//	$scrypt$ln=15,r=8,p=1$<salt>
+//	$pbkdf2-sha256$i=600000$<salt>
+//
+// salt 는 패딩 없는 base64 로 인코딩한다.
+
+// KDFAlgorithm 키 유도 알고리즘
+type KDFAlgorithm string
+
+const (
+	KDFArgon2id     KDFAlgorithm = "argon2id"
+	KDFScrypt       KDFAlgorithm = "scrypt"
+	KDFPBKDF2SHA256 KDFAlgorithm = "pbkdf2-sha256"
+)
+
+// SaltSize 기본 salt 길이
+const SaltSize = 16
+
+// minSaltSize 파싱 시 허용하는 최소 salt 길이
+const minSaltSize = 8
+
+// 파싱 시 허용하는 최대 비용 (기본값의 수 배, 저장되었거나 외부에서 받은 문자열로 메모리나 CPU 를 고갈시키지 않도록)
+const (
+	maxArgon2Memory     = 512 * 1024 // KiB
+	maxArgon2Time       = 16
+	maxScryptLogN       = 20
+	maxScryptR          = 32
+	maxScryptP          = 16
+	maxScryptMemory     = 1 << 30 // 128 * N * r 바이트
+	maxPBKDF2Iterations = 5000000
+)
+
+// KDFParams 키 유도 파라미터
+type KDFParams struct {
+	Algorithm KDFAlgorithm
+	Salt      []byte
+
+	// argon2id
+	Time    uint32 // 반복 횟수
+	Memory  uint32 // 메모리 사용량 (KiB)
+	Threads uint8  // 병렬도
+
+	// scrypt
+	N int // CPU/메모리 비용 (2의 거듭제곱)
+	R int // 블록 크기
+	P int // 병렬도
+
+	// pbkdf2-sha256
+	Iterations int // 반복 횟수
+}
+
+// DefaultKDFParams 알고리즘별 기본 비용 파라미터 (salt 는 비어있음)
+func DefaultKDFParams(algorithm KDFAlgorithm) (KDFParams, error) {
+	switch algorithm {
+	case KDFArgon2id:
+		return KDFParams{Algorithm: algorithm, Time: 3, Memory: 64 * 1024, Threads: 4}, nil
+	case KDFScrypt:
+		return KDFParams{Algorithm: algorithm, N: 1 << 15, R: 8, P: 1}, nil
+	case KDFPBKDF2SHA256:
+		return KDFParams{Algorithm: algorithm, Iterations: 600000}, nil
+	default:
+		return KDFParams{}, fmt.Errorf("지원하지 않는 KDF 알고리즘: %s", algorithm)
+	}
+}
+
+// NewSalt 임의의 salt 생성
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(randReader, salt); err != nil {
+		return nil, fmt.Errorf("salt 생성 실패: %w", err)
+	}
+	return salt, nil
+}
+
+// validate 파라미터 검증
+func (p KDFParams) validate() error {
+	if len(p.Salt) < minSaltSize {
+		return fmt.Errorf("salt 는 %d바이트 이상이어야 함: 현재 %d 바이트", minSaltSize, len(p.Salt))
+	}
+
+	switch p.Algorithm {
+	case KDFArgon2id:
+		if p.Time < 1 || p.Memory < 8*uint32(p.Threads) || p.Threads < 1 {
+			return fmt.Errorf("잘못된 argon2id 파라미터: t=%d, m=%d, p=%d", p.Time, p.Memory, p.Threads)
+		}
+	case KDFScrypt:
+		if p.N <= 1 || p.N&(p.N-1) != 0 || p.R < 1 || p.P < 1 {
+			return fmt.Errorf("잘못된 scrypt 파라미터: N=%d, r=%d, p=%d", p.N, p.R, p.P)
+		}
+	case KDFPBKDF2SHA256:
+		if p.Iterations < 1 {
+			return fmt.Errorf("잘못된 pbkdf2 파라미터: i=%d", p.Iterations)
+		}
+	default:
+		return fmt.Errorf("지원하지 않는 KDF 알고리즘: %s", p.Algorithm)
+	}
+	return nil
+}
+
+// String PHC 형식 파라미터 문자열
+func (p KDFParams) String() string {
+	salt := base64.RawStdEncoding.EncodeToString(p.Salt)
+	switch p.Algorithm {
+	case KDFArgon2id:
+		return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s", p.Algorithm, argon2.Version, p.Memory, p.Time, p.Threads, salt)
+	case KDFScrypt:
+		return fmt.Sprintf("$%s$ln=%d,r=%d,p=%d$%s", p.Algorithm, bits.TrailingZeros(uint(p.N)), p.R, p.P, salt)
+	case KDFPBKDF2SHA256:
+		return fmt.Sprintf("$%s$i=%d$%s", p.Algorithm, p.Iterations, salt)
+	default:
+		return ""
+	}
+}
+
+// ParseKDFParams PHC 형식 파라미터 문자열 파싱
+func ParseKDFParams(encoded string) (KDFParams, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 4 || parts[0] != "" {
+		return KDFParams{}, fmt.Errorf("잘못된 KDF 파라미터 문자열: %q", encoded)
+	}
+
+	p := KDFParams{Algorithm: KDFAlgorithm(parts[1])}
+	fields := parts[2:]
+
+	// argon2id 는 버전 필드가 먼저 온다
+	if p.Algorithm == KDFArgon2id {
+		if len(fields) != 3 || fields[0] != fmt.Sprintf("v=%d", argon2.Version) {
+			return KDFParams{}, fmt.Errorf("지원하지 않는 argon2id 버전: %q", encoded)
+		}
+		fields = fields[1:]
+	}
+	if len(fields) != 2 {
+		return KDFParams{}, fmt.Errorf("잘못된 KDF 파라미터 문자열: %q", encoded)
+	}
+
+	values, err := parsePHCValues(fields[0])
+	if err != nil {
+		return KDFParams{}, err
+	}
+
+	switch p.Algorithm {
+	case KDFArgon2id:
+		p.Memory = uint32(values["m"])
+		p.Time = uint32(values["t"])
+		p.Threads = uint8(values["p"])
+		if values["p"] > 255 {
+			return KDFParams{}, fmt.Errorf("argon2id 병렬도가 너무 큼: %d", values["p"])
+		}
+	case KDFScrypt:
+		if values["ln"] < 1 || values["ln"] > 30 {
+			return KDFParams{}, fmt.Errorf("잘못된 scrypt 비용: ln=%d", values["ln"])
+		}
+		p.N = 1 << values["ln"]
+		p.R = int(values["r"])
+		p.P = int(values["p"])
+	case KDFPBKDF2SHA256:
+		p.Iterations = int(values["i"])
+	}
+
+	p.Salt, err = base64.RawStdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return KDFParams{}, fmt.Errorf("salt 디코딩 실패: %w", err)
+	}
+
+	if err = p.validate(); err != nil {
+		return KDFParams{}, err
+	}
+	if err = p.checkLimits(); err != nil {
+		return KDFParams{}, err
+	}
+	return p, nil
+}
+
+// checkLimits 파싱한 비용이 허용 최대값을 넘으면 ErrInvalidConfig
+func (p KDFParams) checkLimits() error {
+	switch p.Algorithm {
+	case KDFArgon2id:
+		if p.Memory > maxArgon2Memory || p.Time > maxArgon2Time {
+			return newError(ErrInvalidConfig, "argon2id 비용이 허용 최대값을 넘음: m=%d (최대 %d), t=%d (최대 %d)", p.Memory, maxArgon2Memory, p.Time, maxArgon2Time)
+		}
+	case KDFScrypt:
+		if p.N > 1<<maxScryptLogN || p.R > maxScryptR || p.P > maxScryptP || 128*p.N*p.R > maxScryptMemory {
+			return newError(ErrInvalidConfig, "scrypt 비용이 허용 최대값을 넘음: N=%d, r=%d, p=%d", p.N, p.R, p.P)
+		}
+	case KDFPBKDF2SHA256:
+		if p.Iterations > maxPBKDF2Iterations {
+			return newError(ErrInvalidConfig, "pbkdf2 반복 횟수가 허용 최대값을 넘음: i=%d (최대 %d)", p.Iterations, maxPBKDF2Iterations)
+		}
+	}
+	return nil
+}
+
+// parsePHCValues "k=v,k=v" 형식 파싱
+func parsePHCValues(s string) (map[string]uint64, error) {
+	values := make(map[string]uint64)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("잘못된 KDF 파라미터: %q", kv)
+		}
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("잘못된 KDF 파라미터 값 %q: %w", kv, err)
+		}
+		values[k] = n
+	}
+	return values, nil
+}
+
+// DeriveKey 패스프레이즈와 파라미터로 32바이트 키 유도
+func DeriveKey(passphrase string, params KDFParams) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, newError(ErrEmptyInput, "passphrase는 비어있을 수 없습니다")
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	switch params.Algorithm {
+	case KDFArgon2id:
+		return argon2.IDKey([]byte(passphrase), params.Salt, params.Time, params.Memory, params.Threads, KeySize), nil
+	case KDFScrypt:
+		key, err := scrypt.Key([]byte(passphrase), params.Salt, params.N, params.R, params.P, KeySize)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt 키 유도 실패: %w", err)
+		}
+		return key, nil
+	default:
+		key, err := pbkdf2.Key(sha256.New, passphrase, params.Salt, params.Iterations, KeySize)
+		if err != nil {
+			return nil, fmt.Errorf("pbkdf2 키 유도 실패: %w", err)
+		}
+		return key, nil
+	}
+}
+
+// DeriveKeyFromPassphrase 새 salt 와 기본 파라미터로 키를 유도하고 PHC 파라미터 문자열을 함께 반환
+func DeriveKeyFromPassphrase(passphrase string, algorithm KDFAlgorithm) ([]byte, string, error) {
+	params, err := DefaultKDFParams(algorithm)
+	if err != nil {
+		return nil, "", err
+	}
+	if params.Salt, err = NewSalt(); err != nil {
+		return nil, "", err
+	}
+
+	key, err := DeriveKey(passphrase, params)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, params.String(), nil
+}
+
+// DeriveKeyWithParams 저장해 둔 PHC 파라미터 문자열로 같은 키를 다시 유도
+func DeriveKeyWithParams(passphrase string, encodedParams string) ([]byte, error) {
+	params, err := ParseKDFParams(encodedParams)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveKey(passphrase, params)
+}
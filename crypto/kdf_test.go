@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// 테스트용 저비용 파라미터
+func testKDFParams(t *testing.T, algorithm KDFAlgorithm) KDFParams {
+	salt, err := NewSalt()
+	require.NoError(t, err)
+
+	switch algorithm {
+	case KDFArgon2id:
+		return KDFParams{Algorithm: algorithm, Salt: salt, Time: 1, Memory: 1024, Threads: 1}
+	case KDFScrypt:
+		return KDFParams{Algorithm: algorithm, Salt: salt, N: 1 << 10, R: 8, P: 1}
+	default:
+		return KDFParams{Algorithm: algorithm, Salt: salt, Iterations: 1000}
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	passphrase := "Okestro2018@"
+
+	for _, algorithm := range []KDFAlgorithm{KDFArgon2id, KDFScrypt, KDFPBKDF2SHA256} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			params := testKDFParams(t, algorithm)
+
+			key, err := DeriveKey(passphrase, params)
+			require.NoError(t, err)
+			require.Len(t, key, KeySize)
+
+			encoded := params.String()
+			require.True(t, strings.HasPrefix(encoded, "$"+string(algorithm)+"$"), encoded)
+
+			parsed, err := ParseKDFParams(encoded)
+			require.NoError(t, err)
+			require.Equal(t, params, parsed)
+
+			again, err := DeriveKeyWithParams(passphrase, encoded)
+			require.NoError(t, err)
+			require.Equal(t, key, again)
+
+			other, err := DeriveKeyWithParams("wrong-passphrase", encoded)
+			require.NoError(t, err)
+			require.NotEqual(t, key, other)
+
+			// 같은 패스프레이즈라도 salt 가 다르면 다른 키
+			params.Salt, err = NewSalt()
+			require.NoError(t, err)
+			salted, err := DeriveKey(passphrase, params)
+			require.NoError(t, err)
+			require.NotEqual(t, key, salted)
+		})
+	}
+
+	t.Run("DeriveKeyFromPassphrase", func(t *testing.T) {
+		key, encoded, err := DeriveKeyFromPassphrase(passphrase, KDFPBKDF2SHA256)
+		require.NoError(t, err)
+		require.Contains(t, encoded, "i=600000")
+
+		again, err := DeriveKeyWithParams(passphrase, encoded)
+		require.NoError(t, err)
+		require.Equal(t, key, again)
+
+		encrypted, err := Encrypt("secret", key)
+		require.NoError(t, err)
+		decrypted, err := Decrypt(encrypted, again)
+		require.NoError(t, err)
+		require.Equal(t, "secret", decrypted)
+	})
+
+	t.Run("InvalidParams", func(t *testing.T) {
+		_, err := DeriveKey("", testKDFParams(t, KDFScrypt))
+		require.Error(t, err)
+
+		_, err = DefaultKDFParams("md5")
+		require.Error(t, err)
+
+		for _, encoded := range []string{
+			"",
+			"argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ",
+			"$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHQ",
+			"$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHQ",
+			"$scrypt$ln=0,r=8,p=1$c2FsdHNhbHQ",
+			"$scrypt$ln=10,r=8,p=1$c2FsdA",
+			"$pbkdf2-sha256$i=abc$c2FsdHNhbHQ",
+			"$pbkdf2-sha256$i=1000$!!!",
+			"$bcrypt$i=1000$c2FsdHNhbHQ",
+		} {
+			_, err := ParseKDFParams(encoded)
+			require.Error(t, err, encoded)
+		}
+	})
+
+	t.Run("CostLimits", func(t *testing.T) {
+		for _, encoded := range []string{
+			"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdHNhbHQ",
+			"$argon2id$v=19$m=65536,t=4294967295,p=1$c2FsdHNhbHQ",
+			"$scrypt$ln=30,r=8,p=1$c2FsdHNhbHQ",
+			"$scrypt$ln=15,r=4294967295,p=1$c2FsdHNhbHQ",
+			"$scrypt$ln=15,r=8,p=4294967295$c2FsdHNhbHQ",
+			"$scrypt$ln=20,r=32,p=1$c2FsdHNhbHQ",
+			"$pbkdf2-sha256$i=4294967295$c2FsdHNhbHQ",
+		} {
+			_, err := ParseKDFParams(encoded)
+			require.ErrorIs(t, err, ErrInvalidConfig, encoded)
+		}
+
+		// 기본값은 허용 범위 안
+		for _, algorithm := range []KDFAlgorithm{KDFArgon2id, KDFScrypt, KDFPBKDF2SHA256} {
+			params := testKDFParams(t, algorithm)
+			defaults, err := DefaultKDFParams(algorithm)
+			require.NoError(t, err)
+			defaults.Salt = params.Salt
+			_, err = ParseKDFParams(defaults.String())
+			require.NoError(t, err)
+		}
+	})
+}
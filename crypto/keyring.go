@@ -0,0 +1,460 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Keyring 이름이 붙은 여러 개의 키를 보관하고 그중 하나를 기본(primary) 키로 사용
+//
+// 암호화는 항상 기본 키로 수행하며 봉투에 키 ID 를 기록한다.
+// 복호화는 봉투에 기록된 키 ID 의 키를 사용하고, 키 ID 가 없거나(기존 형식 포함)
+// 키링에 없는 ID 이면 활성화된 모든 키를 차례로 시도한다.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]*keyringEntry
+	primary string
+	usage   *UsageTracker
+}
+
+type keyringEntry struct {
+	cipher *Cipher
+	active bool
+}
+
+// ReencryptionResult 재암호화 결과를 저장하는 구조체
+type ReencryptionResult struct {
+	Original    string // 기존 암호문
+	Reencrypted string // 기본 키로 다시 암호화된 암호문
+	Error       error  // 발생한 오류 (있는 경우, errors.Is 로 ErrAuthenticationFailed 등 분류 확인)
+}
+
+// NewKeyring 기본 키를 지정하여 키링 생성
+func NewKeyring(primaryKeyID string, primaryKey []byte) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]*keyringEntry)}
+	if err := k.Add(primaryKeyID, primaryKey); err != nil {
+		return nil, err
+	}
+	k.primary = primaryKeyID
+	return k, nil
+}
+
+// Add 키를 활성 상태로 추가 (이미 있는 ID 면 오류)
+func (k *Keyring) Add(keyID string, key []byte) error {
+	return k.AddWithAlgorithm(keyID, key, AlgorithmAES256GCM)
+}
+
+// AddWithAlgorithm 기본 키가 되었을 때 지정한 알고리즘으로 암호화하는 키 추가
+func (k *Keyring) AddWithAlgorithm(keyID string, key []byte, algorithm Algorithm) error {
+	if len(keyID) == 0 {
+		return newError(ErrEmptyInput, "키 ID 는 비어있을 수 없습니다")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[keyID]; ok {
+		return fmt.Errorf("이미 등록된 키 ID: %s", keyID)
+	}
+	c, err := NewCipherWithConfig(key, CipherConfig{KeyID: keyID, Algorithm: algorithm, Usage: k.usage})
+	if err != nil {
+		return err
+	}
+	k.keys[keyID] = &keyringEntry{cipher: c, active: true}
+	return nil
+}
+
+// TrackUsage 모든 키의 암호화 횟수를 tracker 로 집계 (nil 이면 집계 중지)
+func (k *Keyring) TrackUsage(tracker *UsageTracker) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, entry := range k.keys {
+		entry.cipher = entry.cipher.withUsage(tracker)
+	}
+	k.usage = tracker
+}
+
+// SetPrimary 기본 키 변경 (활성 상태인 키만 가능)
+func (k *Keyring) SetPrimary(keyID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry, ok := k.keys[keyID]
+	if !ok {
+		return &KeyIDError{KeyID: keyID}
+	}
+	if !entry.active {
+		return fmt.Errorf("비활성화된 키는 기본 키가 될 수 없습니다: %s", keyID)
+	}
+	k.primary = keyID
+	return nil
+}
+
+// Rotate 새 키를 추가하고 기본 키로 지정, 이전 키들은 복호화용으로 남는다
+func (k *Keyring) Rotate(keyID string, key []byte) error {
+	if err := k.Add(keyID, key); err != nil {
+		return err
+	}
+	return k.SetPrimary(keyID)
+}
+
+// Deactivate 키를 비활성화하여 더 이상 복호화에 사용하지 않음 (기본 키는 불가)
+func (k *Keyring) Deactivate(keyID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry, ok := k.keys[keyID]
+	if !ok {
+		return &KeyIDError{KeyID: keyID}
+	}
+	if keyID == k.primary {
+		return fmt.Errorf("기본 키는 비활성화할 수 없습니다: %s", keyID)
+	}
+	entry.active = false
+	return nil
+}
+
+// Remove 키를 키링에서 제거 (기본 키는 불가)
+func (k *Keyring) Remove(keyID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[keyID]; !ok {
+		return &KeyIDError{KeyID: keyID}
+	}
+	if keyID == k.primary {
+		return fmt.Errorf("기본 키는 제거할 수 없습니다: %s", keyID)
+	}
+	delete(k.keys, keyID)
+	return nil
+}
+
+// Primary 기본 키 ID
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// KeyIDs 활성화된 키 ID 목록 (기본 키가 맨 앞)
+func (k *Keyring) KeyIDs() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	ids := make([]string, 0, len(k.keys))
+	for id, entry := range k.keys {
+		if entry.active && id != k.primary {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return append([]string{k.primary}, ids...)
+}
+
+// primaryCipher 기본 키의 Cipher
+func (k *Keyring) primaryCipher() *Cipher {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[k.primary].cipher
+}
+
+// candidateCiphers 복호화에 시도할 Cipher 목록, 봉투에 기록된 키가 활성 상태면 그 키만 반환
+func (k *Keyring) candidateCiphers(keyID string) []*Cipher {
+	k.mu.RLock()
+	entry, ok := k.keys[keyID]
+	k.mu.RUnlock()
+	if ok && entry.active {
+		return []*Cipher{entry.cipher}
+	}
+
+	ids := k.KeyIDs()
+	ciphers := make([]*Cipher, 0, len(ids))
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, id := range ids {
+		if entry, ok := k.keys[id]; ok && entry.active {
+			ciphers = append(ciphers, entry.cipher)
+		}
+	}
+	return ciphers
+}
+
+// Encrypt 기본 키로 암호화
+func (k *Keyring) Encrypt(content string) (string, error) {
+	return k.EncryptWithAAD(content, nil)
+}
+
+// EncryptWithAAD 기본 키로 aad 에 묶어 암호화
+func (k *Keyring) EncryptWithAAD(content string, aad []byte) (string, error) {
+	return k.primaryCipher().EncryptWithAAD(content, aad)
+}
+
+// Decrypt 봉투의 키 ID 에 해당하는 키, 없으면 활성화된 모든 키로 복호화 시도
+func (k *Keyring) Decrypt(encryptedText string) (string, error) {
+	return k.DecryptWithAAD(encryptedText, nil)
+}
+
+// DecryptWithAAD 암호화할 때와 같은 aad 로 복호화
+func (k *Keyring) DecryptWithAAD(encryptedText string, aad []byte) (string, error) {
+	if len(encryptedText) == 0 {
+		return "", newError(ErrEmptyInput, "encryptedText는 비어있을 수 없습니다")
+	}
+
+	keyID := envelopeKeyID(encryptedText)
+	var lastErr error
+	for _, c := range k.candidateCiphers(keyID) {
+		decrypted, err := c.DecryptWithAAD(encryptedText, aad)
+		if err == nil {
+			return decrypted, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		return "", newError(ErrUnknownKeyID, "복호화에 사용할 수 있는 활성 키가 없습니다")
+	}
+	return "", k.unknownKeyError(keyID, lastErr)
+}
+
+// unknownKeyError 봉투의 키 ID 가 활성 키가 아니어서 다른 키로 시도했다가 실패한 경우 ErrUnknownKeyID 로 감싼다
+func (k *Keyring) unknownKeyError(keyID string, err error) error {
+	if keyID == "" || errors.Is(err, ErrUnknownKeyID) {
+		return err
+	}
+
+	k.mu.RLock()
+	entry, ok := k.keys[keyID]
+	k.mu.RUnlock()
+	if ok && entry.active {
+		return err
+	}
+	return &KeyIDError{KeyID: keyID, Err: err}
+}
+
+// BulkEncrypt 기본 키로 동시성 제한이 있는 대량 암호화
+func (k *Keyring) BulkEncrypt(contents []string, concurrencyLimit int) []EncryptionResult {
+	results, _ := k.primaryCipher().bulkEncrypt(context.Background(), contents, nil, concurrencyLimit)
+	return results
+}
+
+// BulkEncryptWithAAD 기본 키로 항목별 aad 를 적용하는 대량 암호화
+func (k *Keyring) BulkEncryptWithAAD(contents []string, aads [][]byte, concurrencyLimit int) []EncryptionResult {
+	if len(aads) != len(contents) {
+		return aadCountEncryptionResults(contents, aads)
+	}
+	results, _ := k.primaryCipher().bulkEncrypt(context.Background(), contents, aads, concurrencyLimit)
+	return results
+}
+
+// BulkDecrypt 동시성 제한이 있는 대량 복호화
+//
+// 같은 키 ID 를 가진 암호문끼리 묶어 해당 키의 Cipher 로 처리하고,
+// 실패한 항목은 다음 후보 키로 다시 시도한다.
+func (k *Keyring) BulkDecrypt(encryptedTexts []string, concurrencyLimit int) []DecryptionResult {
+	results, _ := k.bulkDecrypt(context.Background(), encryptedTexts, nil, concurrencyLimit)
+	return results
+}
+
+// BulkDecryptWithAAD 항목별 aad 를 적용하는 대량 복호화
+func (k *Keyring) BulkDecryptWithAAD(encryptedTexts []string, aads [][]byte, concurrencyLimit int) []DecryptionResult {
+	if len(aads) != len(encryptedTexts) {
+		return aadCountDecryptionResults(encryptedTexts, aads)
+	}
+	results, _ := k.bulkDecrypt(context.Background(), encryptedTexts, aads, concurrencyLimit)
+	return results
+}
+
+// bulkDecrypt 키 ID 별로 후보 키를 차례로 시도하는 대량 복호화
+//
+// context 가 취소되면 더 시도하지 않고, 결과가 확정되지 않은 항목에 context 오류를 담는다.
+func (k *Keyring) bulkDecrypt(ctx context.Context, encryptedTexts []string, aads [][]byte, concurrencyLimit int) ([]DecryptionResult, error) {
+	results := make([]DecryptionResult, len(encryptedTexts))
+	settled := make([]bool, len(encryptedTexts))
+
+	// 키 ID 별로 그룹화
+	groups := make(map[string][]int)
+	for i, text := range encryptedTexts {
+		results[i].Encrypted = text
+		keyID := envelopeKeyID(text)
+		groups[keyID] = append(groups[keyID], i)
+	}
+
+	for keyID, remaining := range groups {
+		ciphers := k.candidateCiphers(keyID)
+		if len(ciphers) == 0 {
+			for _, i := range remaining {
+				results[i].Error = newError(ErrUnknownKeyID, "복호화에 사용할 수 있는 활성 키가 없습니다")
+				settled[i] = true
+			}
+			continue
+		}
+
+		for _, c := range ciphers {
+			if ctx.Err() != nil {
+				break
+			}
+			texts := make([]string, len(remaining))
+			var groupAADs [][]byte
+			if aads != nil {
+				groupAADs = make([][]byte, len(remaining))
+			}
+			for j, i := range remaining {
+				texts[j] = encryptedTexts[i]
+				if aads != nil {
+					groupAADs[j] = aads[i]
+				}
+			}
+
+			var failed []int
+			decrypted, _ := c.bulkDecrypt(ctx, texts, groupAADs, concurrencyLimit)
+			for j, result := range decrypted {
+				results[remaining[j]] = result
+				if result.Error != nil {
+					failed = append(failed, remaining[j])
+					continue
+				}
+				settled[remaining[j]] = true
+			}
+
+			remaining = failed
+			if len(remaining) == 0 {
+				break
+			}
+		}
+
+		if ctx.Err() != nil {
+			continue
+		}
+		for _, i := range remaining {
+			results[i].Error = k.unknownKeyError(keyID, results[i].Error)
+			settled[i] = true
+		}
+	}
+
+	err := ctx.Err()
+	if err != nil {
+		for i := range results {
+			if !settled[i] {
+				results[i] = DecryptionResult{Encrypted: encryptedTexts[i], Error: err}
+			}
+		}
+	}
+	return results, err
+}
+
+// Reencrypt 기존 키(또는 기존 형식)로 암호화된 값을 복호화한 뒤 기본 키로 다시 암호화
+//
+// 이미 기본 키로 암호화된 값은 기본 키로 열리는지 확인한 뒤 그대로 반환하고, 열리지 않으면 오류를 담는다.
+func (k *Keyring) Reencrypt(encryptedTexts []string, concurrencyLimit int) []ReencryptionResult {
+	results, _ := k.reencrypt(context.Background(), encryptedTexts, nil, concurrencyLimit)
+	return results
+}
+
+// ReencryptContext 취소 가능한 재암호화
+//
+// context 가 취소되면 처리하지 못한 항목에 context 오류를 담고 그 오류를 함께 반환한다.
+func (k *Keyring) ReencryptContext(ctx context.Context, encryptedTexts []string, concurrencyLimit int) ([]ReencryptionResult, error) {
+	return k.reencrypt(ctx, encryptedTexts, nil, concurrencyLimit)
+}
+
+// ReencryptWithAAD 항목별 aad 를 적용하는 재암호화, 기존 형식 값도 이때 aad 에 묶인다
+func (k *Keyring) ReencryptWithAAD(encryptedTexts []string, aads [][]byte, concurrencyLimit int) []ReencryptionResult {
+	if len(aads) != len(encryptedTexts) {
+		results := make([]ReencryptionResult, len(encryptedTexts))
+		err := aadCountError(len(encryptedTexts), len(aads))
+		for i, text := range encryptedTexts {
+			results[i] = ReencryptionResult{
+				Original: text,
+				Error:    err,
+			}
+		}
+		return results
+	}
+	results, _ := k.reencrypt(context.Background(), encryptedTexts, aads, concurrencyLimit)
+	return results
+}
+
+func (k *Keyring) reencrypt(ctx context.Context, encryptedTexts []string, aads [][]byte, concurrencyLimit int) ([]ReencryptionResult, error) {
+	results := make([]ReencryptionResult, len(encryptedTexts))
+	primaryID := k.Primary()
+
+	var indexes, currentIndexes []int
+	var pending, current []string
+	var pendingAADs, decryptAADs, currentAADs [][]byte
+	for i, text := range encryptedTexts {
+		results[i].Original = text
+		if IsEnvelope(text) && envelopeKeyID(text) == primaryID {
+			currentIndexes = append(currentIndexes, i)
+			current = append(current, text)
+			currentAADs = append(currentAADs, itemAAD(aads, i))
+			continue
+		}
+		indexes = append(indexes, i)
+		pending = append(pending, text)
+		pendingAADs = append(pendingAADs, itemAAD(aads, i))
+
+		// 기존 형식은 aad 없이 복호화하고 재암호화할 때 aad 에 묶는다
+		if IsEnvelope(text) {
+			decryptAADs = append(decryptAADs, itemAAD(aads, i))
+		} else {
+			decryptAADs = append(decryptAADs, nil)
+		}
+	}
+	if aads == nil {
+		pendingAADs = nil
+		decryptAADs = nil
+		currentAADs = nil
+	}
+
+	// 0. 이미 기본 키로 암호화된 값은 변조되지 않았는지 열어서 확인
+	verified, _ := k.primaryCipher().bulkDecrypt(ctx, current, currentAADs, concurrencyLimit)
+	for j, result := range verified {
+		if result.Error != nil {
+			results[currentIndexes[j]].Error = result.Error
+			continue
+		}
+		results[currentIndexes[j]].Reencrypted = current[j]
+	}
+
+	// 1. 복호화
+	var plainIndexes []int
+	var plainTexts []string
+	var plainAADs [][]byte
+	decrypted, _ := k.bulkDecrypt(ctx, pending, decryptAADs, concurrencyLimit)
+	for j, result := range decrypted {
+		if result.Error != nil {
+			results[indexes[j]].Error = result.Error
+			continue
+		}
+		plainIndexes = append(plainIndexes, indexes[j])
+		plainTexts = append(plainTexts, result.Decrypted)
+		plainAADs = append(plainAADs, itemAAD(pendingAADs, j))
+	}
+	if aads == nil {
+		plainAADs = nil
+	}
+
+	// 2. 기본 키로 재암호화
+	encrypted, _ := k.primaryCipher().bulkEncrypt(ctx, plainTexts, plainAADs, concurrencyLimit)
+	for j, result := range encrypted {
+		results[plainIndexes[j]].Reencrypted = result.Encrypted
+		results[plainIndexes[j]].Error = result.Error
+	}
+
+	return results, ctx.Err()
+}
+
+// envelopeKeyID 봉투에 기록된 키 ID, 기존 형식이거나 파싱할 수 없으면 빈 문자열
+func envelopeKeyID(encryptedText string) string {
+	if !IsEnvelope(encryptedText) {
+		return ""
+	}
+	env, err := ParseEnvelope(encryptedText)
+	if err != nil {
+		return ""
+	}
+	return env.KeyID
+}
@@ -0,0 +1,138 @@
+package crypto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	oldKey := CreateKeyFromString("keyring-old")
+	newKey := CreateKeyFromString("keyring-new")
+	message := "db-password"
+
+	t.Run("EncryptUsesPrimary", func(t *testing.T) {
+		ring, err := NewKeyring("k1", oldKey)
+		require.NoError(t, err)
+
+		encrypted, err := ring.Encrypt(message)
+		require.NoError(t, err)
+
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		require.Equal(t, "k1", env.KeyID)
+
+		decrypted, err := Decrypt(encrypted, oldKey)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+	})
+
+	t.Run("RotateAndDecrypt", func(t *testing.T) {
+		ring, err := NewKeyring("k1", oldKey)
+		require.NoError(t, err)
+		before, err := ring.Encrypt(message)
+		require.NoError(t, err)
+
+		require.NoError(t, ring.Rotate("k2", newKey))
+		require.Equal(t, "k2", ring.Primary())
+		require.Equal(t, []string{"k2", "k1"}, ring.KeyIDs())
+
+		after, err := ring.Encrypt(message)
+		require.NoError(t, err)
+
+		for _, encrypted := range []string{before, after, encryptLegacy(t, message, oldKey)} {
+			decrypted, err := ring.Decrypt(encrypted)
+			require.NoError(t, err)
+			require.Equal(t, message, decrypted)
+		}
+
+		require.NoError(t, ring.Deactivate("k1"))
+		_, err = ring.Decrypt(before)
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidOperations", func(t *testing.T) {
+		_, err := NewKeyring("", oldKey)
+		require.Error(t, err)
+		_, err = NewKeyring("k1", []byte("short"))
+		require.Error(t, err)
+
+		ring, err := NewKeyring("k1", oldKey)
+		require.NoError(t, err)
+		require.Error(t, ring.Add("k1", newKey))
+		require.Error(t, ring.SetPrimary("missing"))
+		require.Error(t, ring.Deactivate("k1"))
+		require.Error(t, ring.Remove("k1"))
+
+		require.NoError(t, ring.Add("k2", newKey))
+		require.NoError(t, ring.Deactivate("k2"))
+		require.Error(t, ring.SetPrimary("k2"))
+		require.NoError(t, ring.Remove("k2"))
+	})
+
+	t.Run("Reencrypt", func(t *testing.T) {
+		ring, err := NewKeyring("k1", oldKey)
+		require.NoError(t, err)
+
+		originals := []string{"첫번째", "second", "세번째", "fourth"}
+		encrypted := make([]string, 0, len(originals)+1)
+		for _, o := range originals[:2] {
+			e, err := ring.Encrypt(o)
+			require.NoError(t, err)
+			encrypted = append(encrypted, e)
+		}
+		// 키 ID 없는 봉투와 기존 형식
+		e, err := Encrypt(originals[2], oldKey)
+		require.NoError(t, err)
+		encrypted = append(encrypted, e, encryptLegacy(t, originals[3], oldKey))
+
+		require.NoError(t, ring.Rotate("k2", newKey))
+		alreadyPrimary, err := ring.Encrypt("이미 새 키")
+		require.NoError(t, err)
+		// 기본 키 ID 가 기록되었지만 변조된 값
+		tampered, err := ParseEnvelope(alreadyPrimary)
+		require.NoError(t, err)
+		tampered.Ciphertext[0] ^= 0xff
+		encrypted = append(encrypted, alreadyPrimary, "cmp:broken", tampered.Encode())
+
+		results := ring.Reencrypt(encrypted, 2)
+		require.Len(t, results, len(encrypted))
+
+		for i, o := range originals {
+			require.NoError(t, results[i].Error)
+			require.Equal(t, encrypted[i], results[i].Original)
+
+			env, err := ParseEnvelope(results[i].Reencrypted)
+			require.NoError(t, err)
+			require.Equal(t, "k2", env.KeyID)
+
+			decrypted, err := Decrypt(results[i].Reencrypted, newKey)
+			require.NoError(t, err)
+			require.Equal(t, o, decrypted)
+		}
+
+		require.NoError(t, results[4].Error)
+		require.Equal(t, alreadyPrimary, results[4].Reencrypted)
+		require.Error(t, results[5].Error)
+		require.ErrorIs(t, results[6].Error, ErrAuthenticationFailed)
+		require.Empty(t, results[6].Reencrypted)
+
+		// 취소되면 처리하지 못한 항목에 context 오류
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cancelled, err := ring.ReencryptContext(ctx, encrypted, 2)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Len(t, cancelled, len(encrypted))
+		for i, r := range cancelled {
+			require.Equal(t, encrypted[i], r.Original)
+			require.Empty(t, r.Reencrypted)
+			require.ErrorIs(t, r.Error, context.Canceled)
+		}
+
+		// aad 개수가 맞지 않으면 키 없이 바로 오류
+		for _, r := range ring.BulkEncryptWithAAD([]string{"a", "b"}, [][]byte{[]byte("x")}, 2) {
+			require.ErrorContains(t, r.Error, "aad 개수")
+		}
+	})
+}
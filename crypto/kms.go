@@ -0,0 +1,340 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// 데이터 키 봉투 암호화 (envelope encryption)
+//
+// 값마다 임의의 데이터 키(DEK)를 만들어 암호화하고, 데이터 키는 KEK 로 감싸 암호문 헤더에 함께 저장한다.
+// KEK 는 로컬 파일이나 외부 KMS(Vault transit 호환 API)에 둘 수 있다.
+
+// KeyEncryptionKey 데이터 키를 감싸고 푸는 KEK
+type KeyEncryptionKey interface {
+	// ID 암호문에 기록되는 KEK 식별자
+	ID() string
+	// Wrap 데이터 키를 감싼다
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	// Unwrap 감싼 데이터 키를 푼다
+	Unwrap(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// EncryptWithKEK 새 데이터 키로 암호화하고 데이터 키는 KEK 로 감싸 봉투에 저장
+func EncryptWithKEK(ctx context.Context, content string, kek KeyEncryptionKey, aad []byte) (string, error) {
+	if kek == nil || len(content) == 0 {
+		return "", newError(ErrEmptyInput, "kek와 content는 비어있을 수 없습니다")
+	}
+	if err := checkKeyID(kek.ID()); err != nil {
+		return "", err
+	}
+
+	dataKey := make([]byte, KeySize)
+	if _, err := io.ReadFull(randReader, dataKey); err != nil {
+		return "", fmt.Errorf("데이터 키 생성 실패: %w", err)
+	}
+	defer clear(dataKey)
+
+	wrappedKey, err := kek.Wrap(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("데이터 키 감싸기 실패: %w", err)
+	}
+	if len(wrappedKey) > math.MaxUint16 {
+		return "", fmt.Errorf("감싼 데이터 키가 너무 김: %d 바이트", len(wrappedKey))
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	env := &Envelope{
+		Version:    EnvelopeVersion2,
+		KeyID:      kek.ID(),
+		Algorithm:  AlgorithmAES256GCM,
+		WrappedKey: wrappedKey,
+		Nonce:      make([]byte, gcm.NonceSize()),
+	}
+	if _, err = io.ReadFull(randReader, env.Nonce); err != nil {
+		return "", fmt.Errorf("nonce 생성 실패: %w", err)
+	}
+
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, []byte(content), append(env.header(), aad...))
+
+	return env.Encode(), nil
+}
+
+// DecryptWithKEK 봉투의 데이터 키를 KEK 로 풀어 복호화
+func DecryptWithKEK(ctx context.Context, encryptedText string, kek KeyEncryptionKey, aad []byte) (string, error) {
+	if kek == nil || len(encryptedText) == 0 {
+		return "", newError(ErrEmptyInput, "kek와 encryptedText는 비어있을 수 없습니다")
+	}
+
+	env, err := ParseEnvelope(encryptedText)
+	if err != nil {
+		return "", err
+	}
+	if env.Version != EnvelopeVersion2 {
+		return "", newError(ErrMalformedCiphertext, "데이터 키 봉투가 아닙니다")
+	}
+	if env.KeyID != kek.ID() {
+		return "", &KeyIDError{KeyID: env.KeyID, Err: errors.New("다른 KEK 로 감싼 데이터 키입니다")}
+	}
+
+	dataKey, err := kek.Unwrap(ctx, env.WrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("데이터 키 풀기 실패: %w", err)
+	}
+	defer clear(dataKey)
+
+	if len(dataKey) != KeySize {
+		return "", &KeySizeError{Want: KeySize, Got: len(dataKey)}
+	}
+
+	if err := checkEnvelopeAlgorithm(env.Algorithm); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(env.Algorithm, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plainText, err := aead.Open(nil, env.Nonce, env.Ciphertext, append(env.header(), aad...))
+	if err != nil {
+		return "", wrapError(ErrAuthenticationFailed, err, "복호화 실패 (키가 올바르지 않거나 데이터가 변조됨)")
+	}
+
+	return string(plainText), nil
+}
+
+// LocalKEK 로컬 키로 데이터 키를 감싸는 KEK (AES-256-GCM, KEK ID 를 추가 인증 데이터로 사용)
+type LocalKEK struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalKEK 32바이트 키로 LocalKEK 생성
+func NewLocalKEK(id string, key []byte) (*LocalKEK, error) {
+	if len(id) == 0 {
+		return nil, newError(ErrEmptyInput, "KEK ID 는 비어있을 수 없습니다")
+	}
+	if len(key) != KeySize {
+		return nil, &KeySizeError{Want: KeySize, Got: len(key)}
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalKEK{id: id, aead: aead}, nil
+}
+
+// LoadLocalKEK 파일에서 키를 읽어 LocalKEK 생성
+//
+// 파일에는 32바이트 원본 키 또는 그 base64 문자열이 들어있어야 한다.
+func LoadLocalKEK(id string, path string) (*LocalKEK, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("KEK 파일 읽기 실패: %w", err)
+	}
+	defer clear(raw)
+
+	if len(raw) == KeySize {
+		return NewLocalKEK(id, raw)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("KEK 파일 형식 오류 (32바이트 키 또는 base64): %w", err)
+	}
+	defer clear(key)
+
+	return NewLocalKEK(id, key)
+}
+
+// ID KEK 식별자
+func (l *LocalKEK) ID() string {
+	return l.id
+}
+
+// Wrap 데이터 키를 감싼다 (nonce || ciphertext)
+func (l *LocalKEK) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, l.aead.NonceSize())
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return nil, fmt.Errorf("nonce 생성 실패: %w", err)
+	}
+	return l.aead.Seal(nonce, nonce, dataKey, []byte(l.id)), nil
+}
+
+// Unwrap 감싼 데이터 키를 푼다
+func (l *LocalKEK) Unwrap(_ context.Context, wrappedKey []byte) ([]byte, error) {
+	nonceSize := l.aead.NonceSize()
+	if len(wrappedKey) < nonceSize {
+		return nil, newError(ErrMalformedCiphertext, "감싼 데이터 키가 너무 짧습니다")
+	}
+	nonce, ciphertext := wrappedKey[:nonceSize], wrappedKey[nonceSize:]
+
+	dataKey, err := l.aead.Open(nil, nonce, ciphertext, []byte(l.id))
+	if err != nil {
+		return nil, wrapError(ErrAuthenticationFailed, err, "데이터 키 복호화 실패 (KEK 가 올바르지 않거나 데이터가 변조됨)")
+	}
+	return dataKey, nil
+}
+
+// TransitConfig Vault transit 호환 KMS 설정
+type TransitConfig struct {
+	Address   string        // KMS 주소 (예: http://127.0.0.1:8200)
+	MountPath string        // transit 엔진 마운트 경로
+	KeyName   string        // transit 키 이름
+	Token     string        // X-Vault-Token 헤더 값
+	Namespace string        // X-Vault-Namespace 헤더 값 (선택)
+	Timeout   time.Duration // 요청 타임아웃
+}
+
+// DefaultTransitConfig 기본 transit 설정
+func DefaultTransitConfig() TransitConfig {
+	return TransitConfig{
+		Address:   "http://127.0.0.1:8200",
+		MountPath: "transit",
+		Timeout:   10 * time.Second,
+	}
+}
+
+// TransitKEK Vault transit 호환 HTTP API 로 데이터 키를 감싸는 KEK
+//
+//	POST {Address}/v1/{MountPath}/encrypt/{KeyName}  {"plaintext": base64}   -> {"data": {"ciphertext": "vault:v1:..."}}
+//	POST {Address}/v1/{MountPath}/decrypt/{KeyName}  {"ciphertext": "..."}   -> {"data": {"plaintext": base64}}
+type TransitKEK struct {
+	config TransitConfig
+	client *http.Client
+}
+
+// NewTransitKEK TransitKEK 생성
+func NewTransitKEK(config TransitConfig) (*TransitKEK, error) {
+	if config.Address == "" || config.KeyName == "" {
+		return nil, newError(ErrEmptyInput, "transit 주소와 키 이름은 비어있을 수 없습니다")
+	}
+	if config.MountPath == "" {
+		config.MountPath = DefaultTransitConfig().MountPath
+	}
+	// 키 이름과 마운트 경로는 요청 경로에 들어가므로 경로를 바꾸는 값은 거부
+	if !isTransitPathSegment(config.KeyName) {
+		return nil, newError(ErrInvalidConfig, "transit 키 이름에 사용할 수 없는 값: %q", config.KeyName)
+	}
+	for _, segment := range strings.Split(strings.Trim(config.MountPath, "/"), "/") {
+		if !isTransitPathSegment(segment) {
+			return nil, newError(ErrInvalidConfig, "transit 마운트 경로에 사용할 수 없는 값: %q", config.MountPath)
+		}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTransitConfig().Timeout
+	}
+	config.Address = strings.TrimRight(config.Address, "/")
+
+	return &TransitKEK{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// isTransitPathSegment 요청 경로의 한 구간으로 쓸 수 있는 값인지 확인 (빈 값, "/", ".", ".." 거부)
+func isTransitPathSegment(segment string) bool {
+	return segment != "" && segment != "." && segment != ".." && !strings.Contains(segment, "/")
+}
+
+// endpoint operation 요청 URL, 마운트 경로의 각 구간과 키 이름은 경로 이스케이프한다
+func (t *TransitKEK) endpoint(operation string) string {
+	segments := strings.Split(strings.Trim(t.config.MountPath, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s/v1/%s/%s/%s", t.config.Address, strings.Join(segments, "/"), operation, url.PathEscape(t.config.KeyName))
+}
+
+// ID KEK 식별자 (transit 키 이름)
+func (t *TransitKEK) ID() string {
+	return t.config.KeyName
+}
+
+type transitResponse struct {
+	Data struct {
+		Ciphertext string `json:"ciphertext"`
+		Plaintext  string `json:"plaintext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// Wrap transit encrypt 호출, 반환된 ciphertext 문자열을 그대로 감싼 키로 사용
+func (t *TransitKEK) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := t.call(ctx, "encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("transit 응답에 ciphertext 가 없습니다")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Unwrap transit decrypt 호출
+func (t *TransitKEK) Unwrap(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	resp, err := t.call(ctx, "decrypt", map[string]string{
+		"ciphertext": string(wrappedKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("transit 응답 plaintext 디코딩 실패: %w", err)
+	}
+	return dataKey, nil
+}
+
+func (t *TransitKEK) call(ctx context.Context, operation string, body map[string]string) (*transitResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint(operation), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("transit 요청 생성 실패: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.config.Token != "" {
+		req.Header.Set("X-Vault-Token", t.config.Token)
+	}
+	if t.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", t.config.Namespace)
+	}
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("transit %s 요청 실패: %w", operation, err)
+	}
+	defer res.Body.Close()
+
+	var resp transitResponse
+	if err = json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&resp); err != nil && res.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("transit 응답 파싱 실패: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("transit %s 실패 (HTTP %d): %s", operation, res.StatusCode, strings.Join(resp.Errors, "; "))
+	}
+	return &resp, nil
+}
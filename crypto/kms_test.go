@@ -0,0 +1,178 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTransitStandIn Vault transit 호환 테스트 서버
+func newTransitStandIn(t *testing.T, token string) *httptest.Server {
+	inner, err := NewLocalKEK("transit-inner", CreateKeyFromString("transit-stand-in"))
+	require.NoError(t, err)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := func(status int, v any) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(v)
+		}
+		if r.Header.Get("X-Vault-Token") != token {
+			reply(http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/tenant-a":
+			plain, err := base64.StdEncoding.DecodeString(body["plaintext"])
+			require.NoError(t, err)
+			wrapped, err := inner.Wrap(r.Context(), plain)
+			require.NoError(t, err)
+			reply(http.StatusOK, map[string]any{"data": map[string]string{
+				"ciphertext": "vault:v1:" + base64.StdEncoding.EncodeToString(wrapped),
+			}})
+		case "/v1/transit/decrypt/tenant-a":
+			raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body["ciphertext"], "vault:v1:"))
+			if err != nil {
+				reply(http.StatusBadRequest, map[string]any{"errors": []string{"invalid ciphertext"}})
+				return
+			}
+			plain, err := inner.Unwrap(r.Context(), raw)
+			if err != nil {
+				reply(http.StatusBadRequest, map[string]any{"errors": []string{"cipher: message authentication failed"}})
+				return
+			}
+			reply(http.StatusOK, map[string]any{"data": map[string]string{
+				"plaintext": base64.StdEncoding.EncodeToString(plain),
+			}})
+		default:
+			reply(http.StatusNotFound, map[string]any{"errors": []string{"not found"}})
+		}
+	}))
+}
+
+func TestEnvelopeEncryption(t *testing.T) {
+	ctx := context.Background()
+	message := "tenant credential"
+	aad := NewAAD("tenant-a", "provider-1")
+
+	t.Run("LocalKEK", func(t *testing.T) {
+		kek, err := NewLocalKEK("local-1", CreateKeyFromString("local-kek"))
+		require.NoError(t, err)
+
+		encrypted, err := EncryptWithKEK(ctx, message, kek, aad)
+		require.NoError(t, err)
+
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		require.Equal(t, EnvelopeVersion2, env.Version)
+		require.Equal(t, "local-1", env.KeyID)
+		require.NotEmpty(t, env.WrappedKey)
+
+		decrypted, err := DecryptWithKEK(ctx, encrypted, kek, aad)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+
+		_, err = DecryptWithKEK(ctx, encrypted, kek, nil)
+		require.Error(t, err)
+
+		// 데이터 키 봉투는 일반 Decrypt 로 복호화할 수 없음
+		_, err = Decrypt(encrypted, CreateKeyFromString("local-kek"))
+		require.Error(t, err)
+
+		// 같은 ID 의 다른 KEK
+		other, err := NewLocalKEK("local-1", CreateKeyFromString("other"))
+		require.NoError(t, err)
+		_, err = DecryptWithKEK(ctx, encrypted, other, aad)
+		require.Error(t, err)
+
+		// 감싼 데이터 키 변조
+		env.WrappedKey[len(env.WrappedKey)-1] ^= 1
+		_, err = DecryptWithKEK(ctx, env.Encode(), kek, aad)
+		require.Error(t, err)
+	})
+
+	t.Run("LoadLocalKEK", func(t *testing.T) {
+		dir := t.TempDir()
+		key := CreateKeyFromString("file-kek")
+
+		rawPath := filepath.Join(dir, "kek.bin")
+		require.NoError(t, os.WriteFile(rawPath, key, 0600))
+		b64Path := filepath.Join(dir, "kek.b64")
+		require.NoError(t, os.WriteFile(b64Path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+
+		fromRaw, err := LoadLocalKEK("file", rawPath)
+		require.NoError(t, err)
+		fromB64, err := LoadLocalKEK("file", b64Path)
+		require.NoError(t, err)
+
+		encrypted, err := EncryptWithKEK(ctx, message, fromRaw, nil)
+		require.NoError(t, err)
+		decrypted, err := DecryptWithKEK(ctx, encrypted, fromB64, nil)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+
+		badPath := filepath.Join(dir, "bad")
+		require.NoError(t, os.WriteFile(badPath, []byte("not a key"), 0600))
+		_, err = LoadLocalKEK("file", badPath)
+		require.Error(t, err)
+		_, err = LoadLocalKEK("file", filepath.Join(dir, "missing"))
+		require.Error(t, err)
+	})
+
+	t.Run("TransitKEK", func(t *testing.T) {
+		server := newTransitStandIn(t, "s.token")
+		defer server.Close()
+
+		config := DefaultTransitConfig()
+		config.Address = server.URL + "/"
+		config.KeyName = "tenant-a"
+		config.Token = "s.token"
+		kek, err := NewTransitKEK(config)
+		require.NoError(t, err)
+		require.Equal(t, "tenant-a", kek.ID())
+
+		encrypted, err := EncryptWithKEK(ctx, message, kek, aad)
+		require.NoError(t, err)
+
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(env.WrappedKey), "vault:v1:"))
+
+		decrypted, err := DecryptWithKEK(ctx, encrypted, kek, aad)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+
+		config.Token = "wrong"
+		denied, err := NewTransitKEK(config)
+		require.NoError(t, err)
+		_, err = DecryptWithKEK(ctx, encrypted, denied, aad)
+		require.ErrorContains(t, err, "permission denied")
+
+		_, err = NewTransitKEK(TransitConfig{Address: server.URL})
+		require.Error(t, err)
+
+		for _, name := range []string{"../sys", "a/b", ".."} {
+			_, err = NewTransitKEK(TransitConfig{Address: server.URL, KeyName: name})
+			require.ErrorIs(t, err, ErrInvalidConfig, name)
+		}
+		_, err = NewTransitKEK(TransitConfig{Address: server.URL, MountPath: "transit/../sys", KeyName: "tenant-a"})
+		require.ErrorIs(t, err, ErrInvalidConfig)
+
+		// 경로나 쿼리를 바꾸는 문자는 이스케이프
+		escaped, err := NewTransitKEK(TransitConfig{Address: server.URL, MountPath: "team a/transit", KeyName: "key?x=1#y"})
+		require.NoError(t, err)
+		require.Equal(t, server.URL+"/v1/team%20a/transit/encrypt/key%3Fx=1%23y", escaped.endpoint("encrypt"))
+	})
+}
@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// 비밀 바이트 (키, 복호화된 평문)
+//
+// string 은 지울 수 없으므로 키와 민감한 평문은 SecretBytes 로 다루고, 다 쓰면 Destroy 로 0 으로 지운다.
+// fmt, encoding/json, zap 필드로 출력하면 항상 Redacted 로 표시된다.
+// 다른 구조체 필드로 보관할 때는 *SecretBytes 로 두어야 비공개 필드로 출력될 때도 내용이 드러나지 않는다.
+
+// Redacted 비밀 값 대신 출력되는 문자열
+const Redacted = "[REDACTED]"
+
+// SecretBytes 출력되지 않고 명시적으로 지울 수 있는 바이트
+type SecretBytes struct {
+	b []byte
+}
+
+// NewSecretBytes 복사본을 보관하는 SecretBytes 생성, 원본 b 는 0 으로 지운다
+func NewSecretBytes(b []byte) *SecretBytes {
+	s := &SecretBytes{b: append([]byte(nil), b...)}
+	clear(b)
+	return s
+}
+
+// GenerateKey 임의의 32바이트 키 생성
+func GenerateKey() (*SecretBytes, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("키 생성 실패: %w", err)
+	}
+	return &SecretBytes{b: key}, nil
+}
+
+// Bytes 내부 바이트 (복사하지 않으므로 보관하지 말 것, Destroy 후에는 nil)
+func (s *SecretBytes) Bytes() []byte {
+	if s == nil {
+		return nil
+	}
+	return s.b
+}
+
+// Len 바이트 길이
+func (s *SecretBytes) Len() int {
+	return len(s.Bytes())
+}
+
+// Destroy 내용을 0 으로 지우고 더 이상 사용할 수 없게 한다
+func (s *SecretBytes) Destroy() {
+	if s == nil {
+		return
+	}
+	clear(s.b)
+	s.b = nil
+}
+
+// IsDestroyed Destroy 되었는지 여부
+func (s *SecretBytes) IsDestroyed() bool {
+	return s == nil || s.b == nil
+}
+
+// String 항상 Redacted
+func (s SecretBytes) String() string {
+	return Redacted
+}
+
+// GoString %#v 출력도 Redacted
+func (s SecretBytes) GoString() string {
+	return Redacted
+}
+
+// Format 모든 fmt 동사(%v, %s, %x, %d ...)에 Redacted 출력
+func (s SecretBytes) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, Redacted)
+}
+
+// MarshalJSON JSON 으로는 "[REDACTED]" 출력
+func (s SecretBytes) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
+
+// MarshalText 텍스트 인코딩(YAML 등)도 Redacted 출력
+func (s SecretBytes) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+// EncryptBytes SecretBytes 키로 평문 바이트를 암호화해 봉투 형식 암호문 바이트로 반환
+func EncryptBytes(plainText []byte, key *SecretBytes) ([]byte, error) {
+	c, err := newSecretCipher(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.EncryptBytes(plainText)
+}
+
+// DecryptBytes SecretBytes 키로 복호화해 평문을 SecretBytes 로 반환 (다 쓰면 Destroy)
+func DecryptBytes(encrypted []byte, key *SecretBytes) (*SecretBytes, error) {
+	c, err := newSecretCipher(key, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	return c.DecryptBytes(encrypted)
+}
+
+// EncryptBytes 평문 바이트를 암호화해 봉투 형식 암호문 바이트로 반환
+func (c *Cipher) EncryptBytes(plainText []byte) ([]byte, error) {
+	if len(plainText) == 0 {
+		return nil, newError(ErrEmptyInput, "content는 비어있을 수 없습니다")
+	}
+	encrypted, err := c.seal(plainText, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(encrypted), nil
+}
+
+// DecryptBytes 암호문 바이트를 복호화해 평문을 SecretBytes 로 반환 (다 쓰면 Destroy)
+func (c *Cipher) DecryptBytes(encrypted []byte) (*SecretBytes, error) {
+	plainText, err := c.decrypt(string(encrypted), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBytes{b: plainText}, nil
+}
+
+// newSecretCipher SecretBytes 키로 값 하나를 처리할 일회성 Cipher 생성
+//
+// 키 바이트를 복사해 두지 않고 encrypted 봉투의 알고리즘(nil 이면 기본 알고리즘) AEAD 만 만든다.
+// Cipher 는 호출한 함수가 끝나면 버려지므로 SecretBytes 를 Destroy 한 뒤에는 키가 남지 않는다.
+func newSecretCipher(key *SecretBytes, encrypted []byte) (*Cipher, error) {
+	if key.IsDestroyed() {
+		return nil, newError(ErrEmptyInput, "key는 비어있거나 이미 Destroy 되었습니다")
+	}
+	if text := string(encrypted); IsEnvelope(text) {
+		if env, err := ParseEnvelope(text); err == nil {
+			return newOpenCipher(key.Bytes(), env)
+		}
+	}
+	return newCipher(key.Bytes(), DefaultCipherConfig(), false)
+}
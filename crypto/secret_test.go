@@ -0,0 +1,138 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSecretBytes(t *testing.T) {
+	t.Run("NeverPrinted", func(t *testing.T) {
+		secret := NewSecretBytes([]byte("super-secret"))
+
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%d"} {
+			require.Equal(t, Redacted, fmt.Sprintf(format, secret), format)
+			require.Equal(t, Redacted, fmt.Sprintf(format, *secret), format)
+		}
+
+		type holder struct {
+			Key    *SecretBytes
+			Value  SecretBytes
+			hidden *SecretBytes
+		}
+		printed := fmt.Sprintf("%+v", holder{Key: secret, Value: *secret, hidden: secret})
+		require.NotContains(t, printed, "super-secret")
+		require.NotContains(t, printed, fmt.Sprint([]byte("super-secret")))
+
+		encoded, err := json.Marshal(holder{Key: secret, Value: *secret})
+		require.NoError(t, err)
+		require.Equal(t, `{"Key":"[REDACTED]","Value":"[REDACTED]"}`, string(encoded))
+	})
+
+	t.Run("ZapFields", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		log := zap.New(core)
+		secret := NewSecretBytes([]byte("super-secret"))
+
+		log.Info("key", zap.Any("any", secret), zap.Stringer("stringer", secret), zap.Reflect("reflect", secret))
+
+		entry := logs.All()[0]
+		for key, value := range entry.ContextMap() {
+			require.NotContains(t, fmt.Sprint(value), "super-secret", key)
+		}
+
+		enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+		buf, err := enc.EncodeEntry(entry.Entry, entry.Context)
+		require.NoError(t, err)
+		require.NotContains(t, buf.String(), "super-secret")
+		require.Equal(t, 3, strings.Count(buf.String(), Redacted))
+	})
+
+	t.Run("Destroy", func(t *testing.T) {
+		source := []byte("key-material")
+		secret := NewSecretBytes(source)
+		require.Equal(t, make([]byte, len(source)), source)
+
+		underlying := secret.Bytes()
+		require.Equal(t, "key-material", string(underlying))
+		require.Equal(t, 12, secret.Len())
+
+		secret.Destroy()
+		require.True(t, secret.IsDestroyed())
+		require.Nil(t, secret.Bytes())
+		require.Equal(t, make([]byte, 12), underlying)
+
+		// 여러 번 호출하거나 nil 이어도 안전
+		secret.Destroy()
+		var nilSecret *SecretBytes
+		nilSecret.Destroy()
+		require.True(t, nilSecret.IsDestroyed())
+	})
+
+	t.Run("EncryptDecryptBytes", func(t *testing.T) {
+		key, err := GenerateKey()
+		require.NoError(t, err)
+		require.Equal(t, KeySize, key.Len())
+
+		encrypted, err := EncryptBytes([]byte("평문 바이트"), key)
+		require.NoError(t, err)
+		require.True(t, IsEnvelope(string(encrypted)))
+
+		decrypted, err := DecryptBytes(encrypted, key)
+		require.NoError(t, err)
+		require.Equal(t, "평문 바이트", string(decrypted.Bytes()))
+		decrypted.Destroy()
+
+		// 문자열 API 와 호환
+		text, err := Decrypt(string(encrypted), key.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, "평문 바이트", text)
+
+		fromString, err := Encrypt("문자열", key.Bytes())
+		require.NoError(t, err)
+		decrypted, err = DecryptBytes([]byte(fromString), key)
+		require.NoError(t, err)
+		require.Equal(t, "문자열", string(decrypted.Bytes()))
+
+		// 봉투의 알고리즘으로 복호화
+		fromX, err := EncryptWithAlgorithm("다른 알고리즘", key.Bytes(), AlgorithmXChaCha20Poly1305)
+		require.NoError(t, err)
+		decrypted, err = DecryptBytes([]byte(fromX), key)
+		require.NoError(t, err)
+		require.Equal(t, "다른 알고리즘", string(decrypted.Bytes()))
+
+		// Destroy 한 키로는 더 이상 복호화할 수 없다
+		key.Destroy()
+		_, err = DecryptBytes(encrypted, key)
+		require.ErrorIs(t, err, ErrEmptyInput)
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		key, err := GenerateKey()
+		require.NoError(t, err)
+
+		_, err = EncryptBytes(nil, key)
+		require.ErrorIs(t, err, ErrEmptyInput)
+		_, err = DecryptBytes(nil, key)
+		require.ErrorIs(t, err, ErrEmptyInput)
+		_, err = EncryptBytes([]byte("값"), NewSecretBytes([]byte("short")))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+
+		encrypted, err := EncryptBytes([]byte("값"), key)
+		require.NoError(t, err)
+		_, err = DecryptBytes(encrypted[:len(encrypted)-4], key)
+		require.Error(t, err)
+
+		key.Destroy()
+		_, err = EncryptBytes([]byte("값"), key)
+		require.ErrorIs(t, err, ErrEmptyInput)
+		_, err = DecryptBytes(encrypted, nil)
+		require.ErrorIs(t, err, ErrEmptyInput)
+	})
+}
@@ -0,0 +1,275 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// 서명 및 메시지 인증 (Ed25519, HMAC-SHA256)
+//
+// 서명 대상은 도메인 구분자, 알고리즘, 키 ID 와 메시지를 NewAAD 로 묶은 값이므로
+// 서명을 다른 키 ID 나 알고리즘으로 바꿔 붙이면 검증이 실패한다.
+// Ed25519 는 발행자만 개인 키를 가지므로 여러 소비자가 검증하는 경우에 사용하고,
+// HMAC-SHA256 은 발행자와 검증자가 같은 키를 공유하는 내부 구간에 사용한다.
+
+// SignatureAlgorithm 서명 알고리즘
+type SignatureAlgorithm string
+
+const (
+	SignatureEd25519    SignatureAlgorithm = "ed25519"
+	SignatureHMACSHA256 SignatureAlgorithm = "hmac-sha256"
+)
+
+// signatureDomain 서명 대상 도메인 구분자
+const signatureDomain = "cmp-signature-v1"
+
+// Signature 키 ID 와 알고리즘이 붙은 서명 값
+type Signature struct {
+	KeyID     string             `json:"kid"`
+	Algorithm SignatureAlgorithm `json:"alg"`
+	Value     []byte             `json:"sig"`
+}
+
+// Encode 메시지 헤더 등에 넣을 수 있는 JSON 문자열로 직렬화
+func (s *Signature) Encode() string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded)
+}
+
+// ParseSignature Encode 로 직렬화한 서명 파싱
+func ParseSignature(encoded string) (*Signature, error) {
+	var sig Signature
+	if err := json.Unmarshal([]byte(encoded), &sig); err != nil {
+		return nil, wrapError(ErrMalformedCiphertext, err, "서명 형식 오류")
+	}
+	if sig.Algorithm == "" || len(sig.Value) == 0 {
+		return nil, newError(ErrMalformedCiphertext, "서명 알고리즘 또는 값이 비어있습니다")
+	}
+	return &sig, nil
+}
+
+// Signer 메시지에 서명
+type Signer interface {
+	KeyID() string
+	Sign(message []byte) (*Signature, error)
+}
+
+// Verifier 서명 검증, 실패하면 ErrAuthenticationFailed 또는 ErrUnknownKeyID 로 분류되는 오류를 반환
+type Verifier interface {
+	Verify(message []byte, sig *Signature) error
+}
+
+// KeyedVerifier 키 ID 를 가진 Verifier (VerifierSet 에 등록)
+type KeyedVerifier interface {
+	KeyID() string
+	Verifier
+}
+
+// signingInput 서명 대상 바이트
+func signingInput(algorithm SignatureAlgorithm, keyID string, message []byte) []byte {
+	return NewAAD(signatureDomain, string(algorithm), keyID, string(message))
+}
+
+// checkSignature 서명의 키 ID, 알고리즘 확인
+func checkSignature(sig *Signature, keyID string, algorithm SignatureAlgorithm) error {
+	if sig == nil || len(sig.Value) == 0 {
+		return newError(ErrEmptyInput, "서명은 비어있을 수 없습니다")
+	}
+	if sig.KeyID != keyID {
+		return &KeyIDError{KeyID: sig.KeyID}
+	}
+	if sig.Algorithm != algorithm {
+		return newError(ErrAuthenticationFailed, "서명 알고리즘이 맞지 않습니다: %s", sig.Algorithm)
+	}
+	return nil
+}
+
+// Ed25519Signer Ed25519 개인 키로 서명
+type Ed25519Signer struct {
+	keyID      string
+	privateKey ed25519.PrivateKey
+}
+
+// NewEd25519Signer 개인 키로 Ed25519Signer 생성
+func NewEd25519Signer(keyID string, privateKey ed25519.PrivateKey) (*Ed25519Signer, error) {
+	if len(keyID) == 0 {
+		return nil, newError(ErrEmptyInput, "키 ID 는 비어있을 수 없습니다")
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, &KeySizeError{Want: ed25519.PrivateKeySize, Got: len(privateKey)}
+	}
+	return &Ed25519Signer{keyID: keyID, privateKey: privateKey}, nil
+}
+
+// GenerateEd25519Signer 새 Ed25519 키 쌍으로 Ed25519Signer 생성
+func GenerateEd25519Signer(keyID string) (*Ed25519Signer, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("Ed25519 키 생성 실패: %w", err)
+	}
+	return NewEd25519Signer(keyID, privateKey)
+}
+
+// KeyID 서명에 기록되는 키 ID
+func (s *Ed25519Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey 검증자에게 배포할 공개 키
+func (s *Ed25519Signer) PublicKey() ed25519.PublicKey {
+	return s.privateKey.Public().(ed25519.PublicKey)
+}
+
+// Sign 메시지 서명
+func (s *Ed25519Signer) Sign(message []byte) (*Signature, error) {
+	return &Signature{
+		KeyID:     s.keyID,
+		Algorithm: SignatureEd25519,
+		Value:     ed25519.Sign(s.privateKey, signingInput(SignatureEd25519, s.keyID, message)),
+	}, nil
+}
+
+// Verify 자신의 공개 키로 서명 검증
+func (s *Ed25519Signer) Verify(message []byte, sig *Signature) error {
+	return verifyEd25519(s.keyID, s.PublicKey(), message, sig)
+}
+
+// Ed25519Verifier Ed25519 공개 키로 서명 검증
+type Ed25519Verifier struct {
+	keyID     string
+	publicKey ed25519.PublicKey
+}
+
+// NewEd25519Verifier 공개 키로 Ed25519Verifier 생성
+func NewEd25519Verifier(keyID string, publicKey ed25519.PublicKey) (*Ed25519Verifier, error) {
+	if len(keyID) == 0 {
+		return nil, newError(ErrEmptyInput, "키 ID 는 비어있을 수 없습니다")
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, &KeySizeError{Want: ed25519.PublicKeySize, Got: len(publicKey)}
+	}
+	return &Ed25519Verifier{keyID: keyID, publicKey: publicKey}, nil
+}
+
+// KeyID 검증할 서명의 키 ID
+func (v *Ed25519Verifier) KeyID() string {
+	return v.keyID
+}
+
+// Verify 서명 검증
+func (v *Ed25519Verifier) Verify(message []byte, sig *Signature) error {
+	return verifyEd25519(v.keyID, v.publicKey, message, sig)
+}
+
+func verifyEd25519(keyID string, publicKey ed25519.PublicKey, message []byte, sig *Signature) error {
+	if err := checkSignature(sig, keyID, SignatureEd25519); err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, signingInput(SignatureEd25519, keyID, message), sig.Value) {
+		return newError(ErrAuthenticationFailed, "서명 검증 실패 (키가 올바르지 않거나 메시지가 변조됨)")
+	}
+	return nil
+}
+
+// HMACSigner 공유 키로 HMAC-SHA256 메시지 인증 코드를 만들고 검증
+type HMACSigner struct {
+	keyID string
+	key   []byte
+}
+
+// NewHMACSigner HMAC 키로 HMACSigner 생성 (32바이트 이상)
+func NewHMACSigner(keyID string, key []byte) (*HMACSigner, error) {
+	if len(keyID) == 0 {
+		return nil, newError(ErrEmptyInput, "키 ID 는 비어있을 수 없습니다")
+	}
+	if len(key) < KeySize {
+		return nil, &KeySizeError{Want: KeySize, Got: len(key), AtLeast: true}
+	}
+	return &HMACSigner{keyID: keyID, key: append([]byte(nil), key...)}, nil
+}
+
+// KeyID 서명에 기록되는 키 ID
+func (h *HMACSigner) KeyID() string {
+	return h.keyID
+}
+
+// Sign 메시지 인증 코드 생성
+func (h *HMACSigner) Sign(message []byte) (*Signature, error) {
+	return &Signature{
+		KeyID:     h.keyID,
+		Algorithm: SignatureHMACSHA256,
+		Value:     h.mac(message),
+	}, nil
+}
+
+// Verify 메시지 인증 코드를 상수 시간으로 비교
+func (h *HMACSigner) Verify(message []byte, sig *Signature) error {
+	if err := checkSignature(sig, h.keyID, SignatureHMACSHA256); err != nil {
+		return err
+	}
+	if !hmac.Equal(h.mac(message), sig.Value) {
+		return newError(ErrAuthenticationFailed, "서명 검증 실패 (키가 올바르지 않거나 메시지가 변조됨)")
+	}
+	return nil
+}
+
+func (h *HMACSigner) mac(message []byte) []byte {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(signingInput(SignatureHMACSHA256, h.keyID, message))
+	return mac.Sum(nil)
+}
+
+// VerifierSet 서명의 키 ID 로 검증자를 골라 검증 (허용된 발행자 목록)
+type VerifierSet struct {
+	verifiers map[string]Verifier
+}
+
+// NewVerifierSet 검증자 목록으로 VerifierSet 생성 (키 ID 가 겹치면 오류)
+func NewVerifierSet(verifiers ...KeyedVerifier) (*VerifierSet, error) {
+	set := &VerifierSet{verifiers: make(map[string]Verifier, len(verifiers))}
+	for _, v := range verifiers {
+		if _, ok := set.verifiers[v.KeyID()]; ok {
+			return nil, fmt.Errorf("이미 등록된 키 ID: %s", v.KeyID())
+		}
+		set.verifiers[v.KeyID()] = v
+	}
+	return set, nil
+}
+
+// Verify 서명의 키 ID 에 해당하는 검증자로 검증, 없으면 ErrUnknownKeyID
+func (s *VerifierSet) Verify(message []byte, sig *Signature) error {
+	if sig == nil {
+		return newError(ErrEmptyInput, "서명은 비어있을 수 없습니다")
+	}
+	v, ok := s.verifiers[sig.KeyID]
+	if !ok {
+		return &KeyIDError{KeyID: sig.KeyID}
+	}
+	return v.Verify(message, sig)
+}
+
+// CanonicalJSON 서명용 정규화 JSON (공백 제거, 객체 키 정렬, 숫자 표기는 원본 유지)
+func CanonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("JSON 파싱 실패: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("JSON 값 뒤에 데이터가 더 있습니다")
+	}
+
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("JSON 정규화 실패: %w", err)
+	}
+	return canonical, nil
+}
@@ -0,0 +1,150 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatures(t *testing.T) {
+	message := []byte(`{"resource":{"providerId":"p-1"}}`)
+
+	t.Run("Ed25519", func(t *testing.T) {
+		signer, err := GenerateEd25519Signer("collector-1")
+		require.NoError(t, err)
+
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+		require.Equal(t, "collector-1", sig.KeyID)
+		require.Equal(t, SignatureEd25519, sig.Algorithm)
+		require.Len(t, sig.Value, ed25519.SignatureSize)
+
+		verifier, err := NewEd25519Verifier("collector-1", signer.PublicKey())
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(message, sig))
+		require.NoError(t, signer.Verify(message, sig))
+
+		require.ErrorIs(t, verifier.Verify([]byte(`{"resource":{"providerId":"p-2"}}`), sig), ErrAuthenticationFailed)
+
+		other, err := GenerateEd25519Signer("collector-1")
+		require.NoError(t, err)
+		forged, err := other.Sign(message)
+		require.NoError(t, err)
+		require.ErrorIs(t, verifier.Verify(message, forged), ErrAuthenticationFailed)
+	})
+
+	t.Run("KeyIDBoundToSignature", func(t *testing.T) {
+		signer, err := GenerateEd25519Signer("collector-1")
+		require.NoError(t, err)
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+
+		// 같은 공개 키를 다른 키 ID 로 등록해도 키 ID 를 바꿔 붙인 서명은 통과하지 않음
+		verifier, err := NewEd25519Verifier("collector-2", signer.PublicKey())
+		require.NoError(t, err)
+		require.ErrorIs(t, verifier.Verify(message, sig), ErrUnknownKeyID)
+
+		sig.KeyID = "collector-2"
+		require.ErrorIs(t, verifier.Verify(message, sig), ErrAuthenticationFailed)
+	})
+
+	t.Run("HMAC", func(t *testing.T) {
+		key := CreateKeyFromString("hmac-signing-key")
+		signer, err := NewHMACSigner("collector-hmac", key)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+		require.Equal(t, SignatureHMACSHA256, sig.Algorithm)
+		require.NoError(t, signer.Verify(message, sig))
+
+		again, err := signer.Sign(message)
+		require.NoError(t, err)
+		require.Equal(t, sig.Value, again.Value)
+
+		require.ErrorIs(t, signer.Verify(append(message, ' '), sig), ErrAuthenticationFailed)
+
+		wrongKey, err := NewHMACSigner("collector-hmac", CreateKeyFromString("other-key"))
+		require.NoError(t, err)
+		require.ErrorIs(t, wrongKey.Verify(message, sig), ErrAuthenticationFailed)
+
+		sig.Algorithm = SignatureEd25519
+		require.ErrorIs(t, signer.Verify(message, sig), ErrAuthenticationFailed)
+	})
+
+	t.Run("VerifierSet", func(t *testing.T) {
+		ed, err := GenerateEd25519Signer("collector-ed")
+		require.NoError(t, err)
+		edVerifier, err := NewEd25519Verifier("collector-ed", ed.PublicKey())
+		require.NoError(t, err)
+		mac, err := NewHMACSigner("collector-hmac", CreateKeyFromString("hmac-signing-key"))
+		require.NoError(t, err)
+
+		set, err := NewVerifierSet(edVerifier, mac)
+		require.NoError(t, err)
+
+		for _, signer := range []Signer{ed, mac} {
+			sig, err := signer.Sign(message)
+			require.NoError(t, err)
+			require.NoError(t, set.Verify(message, sig))
+		}
+
+		stranger, err := GenerateEd25519Signer("stranger")
+		require.NoError(t, err)
+		sig, err := stranger.Sign(message)
+		require.NoError(t, err)
+		require.ErrorIs(t, set.Verify(message, sig), ErrUnknownKeyID)
+		require.ErrorIs(t, set.Verify(message, nil), ErrEmptyInput)
+
+		_, err = NewVerifierSet(edVerifier, edVerifier)
+		require.Error(t, err)
+	})
+
+	t.Run("EncodeParse", func(t *testing.T) {
+		signer, err := GenerateEd25519Signer("collector-1")
+		require.NoError(t, err)
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+
+		parsed, err := ParseSignature(sig.Encode())
+		require.NoError(t, err)
+		require.Equal(t, sig, parsed)
+		require.NoError(t, signer.Verify(message, parsed))
+
+		_, err = ParseSignature("not json")
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+		_, err = ParseSignature(`{"kid":"k"}`)
+		require.ErrorIs(t, err, ErrMalformedCiphertext)
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		_, err := NewEd25519Signer("k", make([]byte, 10))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+		_, err = NewEd25519Verifier("k", make([]byte, 10))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+		_, err = NewHMACSigner("k", []byte("short"))
+		require.ErrorIs(t, err, ErrInvalidKeySize)
+		_, err = NewHMACSigner("", CreateKeyFromString("key"))
+		require.ErrorIs(t, err, ErrEmptyInput)
+	})
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	a, err := CanonicalJSON([]byte(`{ "b": 1, "a": {"y": [1, 2.50, "x"], "x": null} }`))
+	require.NoError(t, err)
+	require.Equal(t, `{"a":{"x":null,"y":[1,2.50,"x"]},"b":1}`, string(a))
+
+	b, err := CanonicalJSON([]byte(`{"a":{"x":null,"y":[1,2.50,"x"]},"b":1}`))
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	big, err := CanonicalJSON([]byte(`{"id": 12345678901234567890}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"id":12345678901234567890}`, string(big))
+
+	_, err = CanonicalJSON([]byte(`{"a":1} {"b":2}`))
+	require.Error(t, err)
+	_, err = CanonicalJSON([]byte(`{`))
+	require.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+// 결정적 암호화 (AES-SIV, RFC 5297)
+//
+// 같은 키, 같은 aad, 같은 평문은 항상 같은 암호문이 되므로 암호화된 컬럼에
+// WHERE encrypted_col = ? 조회를 할 수 있다.
+//
+// 주의: 이 모드는 설계상 평문이 같은지 여부를 드러낸다. 값의 종류가 적거나(상태 코드, 국가 등)
+// 분포를 추측할 수 있는 컬럼에는 사용하지 말고, 조회가 꼭 필요한 식별자 컬럼에만 사용한다.
+// 키를 교체하면 같은 평문이라도 암호문이 달라지므로 조회 전에 재암호화가 필요하다.
+//
+//	"cmpd:" + base64( version(1) | keyIDLen(1) | keyID | algorithm(1) | siv(16) | ciphertext )
+//
+// 32바이트 키에서 HKDF-SHA256 으로 S2V 용 키와 CTR 용 키(각 32바이트)를 유도한다.
+// 헤더와 aad 는 S2V 의 연관 데이터로 들어간다.
+
+// DeterministicPrefix 결정적 암호문의 접두사
+const DeterministicPrefix = "cmpd:"
+
+// sivKeyInfo SIV 키 유도에 쓰는 HKDF info
+const sivKeyInfo = "cmp-common crypto AES-SIV"
+
+// DeterministicCipher 결정적 암호화를 수행하는 AES-SIV 암호기, 여러 고루틴에서 동시에 사용해도 안전하다
+type DeterministicCipher struct {
+	keyID string
+	mac   *cmac
+	ctr   cipher.Block
+}
+
+// NewDeterministicCipher 키로 DeterministicCipher 생성
+func NewDeterministicCipher(key []byte) (*DeterministicCipher, error) {
+	return NewDeterministicCipherWithKeyID(key, "")
+}
+
+// NewDeterministicCipherWithKeyID 암호문에 키 ID 를 기록하는 DeterministicCipher 생성
+func NewDeterministicCipherWithKeyID(key []byte, keyID string) (*DeterministicCipher, error) {
+	if err := checkKeySize(key); err != nil {
+		return nil, err
+	}
+	if err := checkKeyID(keyID); err != nil {
+		return nil, err
+	}
+
+	sivKey, err := hkdf.Key(sha256.New, key, nil, sivKeyInfo, 2*KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("SIV 키 유도 실패: %w", err)
+	}
+	defer clear(sivKey)
+
+	macBlock, err := aes.NewCipher(sivKey[:KeySize])
+	if err != nil {
+		return nil, fmt.Errorf("AES 블록 생성 실패: %w", err)
+	}
+	ctrBlock, err := aes.NewCipher(sivKey[KeySize:])
+	if err != nil {
+		return nil, fmt.Errorf("AES 블록 생성 실패: %w", err)
+	}
+
+	return &DeterministicCipher{keyID: keyID, mac: newCMAC(macBlock), ctr: ctrBlock}, nil
+}
+
+// EncryptDeterministic 결정적 암호화 (같은 평문은 같은 암호문)
+func EncryptDeterministic(content string, key []byte) (string, error) {
+	d, err := NewDeterministicCipher(key)
+	if err != nil {
+		return "", err
+	}
+	return d.Encrypt(content)
+}
+
+// DecryptDeterministic 결정적 암호문 복호화
+func DecryptDeterministic(encryptedText string, key []byte) (string, error) {
+	d, err := NewDeterministicCipher(key)
+	if err != nil {
+		return "", err
+	}
+	return d.Decrypt(encryptedText)
+}
+
+// IsDeterministic 결정적 암호문인지 여부
+func IsDeterministic(encryptedText string) bool {
+	return strings.HasPrefix(encryptedText, DeterministicPrefix)
+}
+
+// Encrypt 결정적 암호화
+func (d *DeterministicCipher) Encrypt(content string) (string, error) {
+	return d.EncryptWithAAD(content, nil)
+}
+
+// EncryptWithAAD aad 에 묶어 결정적 암호화, 같은 aad 안에서만 같은 암호문이 된다
+func (d *DeterministicCipher) EncryptWithAAD(content string, aad []byte) (string, error) {
+	if len(content) == 0 {
+		return "", newError(ErrEmptyInput, "content는 비어있을 수 없습니다")
+	}
+
+	env := &Envelope{
+		Version:   EnvelopeVersion1,
+		KeyID:     d.keyID,
+		Algorithm: AlgorithmAES256SIV,
+	}
+	env.Nonce, env.Ciphertext = sivSeal(d.mac, d.ctr, []byte(content), env.header(), aad)
+
+	return env.Encode(), nil
+}
+
+// Decrypt 결정적 암호문 복호화
+func (d *DeterministicCipher) Decrypt(encryptedText string) (string, error) {
+	return d.DecryptWithAAD(encryptedText, nil)
+}
+
+// DecryptWithAAD 암호화할 때와 같은 aad 로 복호화
+func (d *DeterministicCipher) DecryptWithAAD(encryptedText string, aad []byte) (string, error) {
+	if len(encryptedText) == 0 {
+		return "", newError(ErrEmptyInput, "encryptedText는 비어있을 수 없습니다")
+	}
+	if !IsDeterministic(encryptedText) {
+		return "", newError(ErrMalformedCiphertext, "결정적 암호문이 아닙니다")
+	}
+
+	env, err := ParseEnvelope(encryptedText)
+	if err != nil {
+		return "", err
+	}
+
+	plainText, err := sivOpen(d.mac, d.ctr, env.Nonce, env.Ciphertext, env.header(), aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plainText), nil
+}
+
+// sivSeal S2V 로 합성 IV 를 만들고 그 IV 로 CTR 암호화
+func sivSeal(mac *cmac, ctr cipher.Block, plaintext []byte, ad ...[]byte) (siv []byte, ciphertext []byte) {
+	siv = mac.s2v(append(ad, plaintext)...)
+	ciphertext = make([]byte, len(plaintext))
+	sivCTR(ctr, siv).XORKeyStream(ciphertext, plaintext)
+	return siv, ciphertext
+}
+
+// sivOpen CTR 복호화 후 합성 IV 검증
+func sivOpen(mac *cmac, ctr cipher.Block, siv []byte, ciphertext []byte, ad ...[]byte) ([]byte, error) {
+	if len(siv) != aes.BlockSize {
+		return nil, newError(ErrMalformedCiphertext, "합성 IV 길이가 올바르지 않습니다")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	sivCTR(ctr, siv).XORKeyStream(plaintext, ciphertext)
+
+	expected := mac.s2v(append(ad, plaintext)...)
+	if subtle.ConstantTimeCompare(expected, siv) != 1 {
+		clear(plaintext)
+		return nil, newError(ErrAuthenticationFailed, "복호화 실패 (키가 올바르지 않거나 데이터가 변조됨)")
+	}
+	return plaintext, nil
+}
+
+// sivCTR 합성 IV 의 31, 63 번째 비트를 지운 값을 초기 카운터로 사용 (RFC 5297 2.6)
+func sivCTR(block cipher.Block, siv []byte) cipher.Stream {
+	q := make([]byte, aes.BlockSize)
+	copy(q, siv)
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+	return cipher.NewCTR(block, q)
+}
+
+// cmac AES-CMAC (RFC 4493)
+type cmac struct {
+	block  cipher.Block
+	k1, k2 []byte
+}
+
+func newCMAC(block cipher.Block) *cmac {
+	l := make([]byte, aes.BlockSize)
+	block.Encrypt(l, l)
+	k1 := dbl(l)
+	return &cmac{block: block, k1: k1, k2: dbl(k1)}
+}
+
+// sum 메시지의 CMAC
+func (c *cmac) sum(msg []byte) []byte {
+	x := make([]byte, aes.BlockSize)
+
+	n := (len(msg) + aes.BlockSize - 1) / aes.BlockSize
+	complete := n > 0 && len(msg)%aes.BlockSize == 0
+	if n == 0 {
+		n = 1
+	}
+
+	for i := 0; i < n-1; i++ {
+		subtle.XORBytes(x, x, msg[i*aes.BlockSize:(i+1)*aes.BlockSize])
+		c.block.Encrypt(x, x)
+	}
+
+	last := make([]byte, aes.BlockSize)
+	copy(last, msg[(n-1)*aes.BlockSize:])
+	if complete {
+		subtle.XORBytes(last, last, c.k1)
+	} else {
+		last[len(msg)-(n-1)*aes.BlockSize] = 0x80
+		subtle.XORBytes(last, last, c.k2)
+	}
+
+	subtle.XORBytes(x, x, last)
+	c.block.Encrypt(x, x)
+	return x
+}
+
+// s2v 여러 입력을 하나의 합성 IV 로 묶는다 (RFC 5297 2.4), 마지막 입력이 평문
+func (c *cmac) s2v(inputs ...[]byte) []byte {
+	d := c.sum(make([]byte, aes.BlockSize))
+	for _, s := range inputs[:len(inputs)-1] {
+		d = dbl(d)
+		subtle.XORBytes(d, d, c.sum(s))
+	}
+
+	last := inputs[len(inputs)-1]
+	var t []byte
+	if len(last) >= aes.BlockSize {
+		t = append([]byte(nil), last...)
+		subtle.XORBytes(t[len(t)-aes.BlockSize:], t[len(t)-aes.BlockSize:], d)
+	} else {
+		t = make([]byte, aes.BlockSize)
+		copy(t, last)
+		t[len(last)] = 0x80
+		subtle.XORBytes(t, t, dbl(d))
+	}
+	return c.sum(t)
+}
+
+// dbl GF(2^128) 에서 2를 곱함
+func dbl(b []byte) []byte {
+	out := make([]byte, len(b))
+	var carry byte
+	for i := len(b) - 1; i >= 0; i-- {
+		out[i] = b[i]<<1 | carry
+		carry = b[i] >> 7
+	}
+	if carry != 0 {
+		out[len(out)-1] ^= 0x87
+	}
+	return out
+}
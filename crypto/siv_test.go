@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministic(t *testing.T) {
+	key := CreateKeyFromString("siv-test-key")
+
+	t.Run("SameInputSameOutput", func(t *testing.T) {
+		a, err := EncryptDeterministic("provider-account-1234", key)
+		require.NoError(t, err)
+		b, err := EncryptDeterministic("provider-account-1234", key)
+		require.NoError(t, err)
+		require.Equal(t, a, b)
+		require.True(t, strings.HasPrefix(a, DeterministicPrefix))
+		require.True(t, IsDeterministic(a))
+
+		other, err := EncryptDeterministic("provider-account-5678", key)
+		require.NoError(t, err)
+		require.NotEqual(t, a, other)
+
+		decrypted, err := DecryptDeterministic(a, key)
+		require.NoError(t, err)
+		require.Equal(t, "provider-account-1234", decrypted)
+	})
+
+	t.Run("AADAndKeyID", func(t *testing.T) {
+		d, err := NewDeterministicCipherWithKeyID(key, "det-1")
+		require.NoError(t, err)
+
+		aad := NewAAD("accounts", "provider_account_id")
+		a, err := d.EncryptWithAAD("값", aad)
+		require.NoError(t, err)
+		b, err := d.EncryptWithAAD("값", NewAAD("accounts", "other_column"))
+		require.NoError(t, err)
+		require.NotEqual(t, a, b)
+
+		env, err := ParseEnvelope(a)
+		require.NoError(t, err)
+		require.Equal(t, "det-1", env.KeyID)
+		require.Equal(t, AlgorithmAES256SIV, env.Algorithm)
+
+		_, err = d.Decrypt(a)
+		require.Error(t, err)
+		decrypted, err := d.DecryptWithAAD(a, aad)
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+	})
+
+	t.Run("LongPlaintext", func(t *testing.T) {
+		content := strings.Repeat("블록 경계를 넘는 평문 ", 10)
+		encrypted, err := EncryptDeterministic(content, key)
+		require.NoError(t, err)
+		decrypted, err := DecryptDeterministic(encrypted, key)
+		require.NoError(t, err)
+		require.Equal(t, content, decrypted)
+	})
+
+	t.Run("WrongKeyOrTampered", func(t *testing.T) {
+		encrypted, err := EncryptDeterministic("값", key)
+		require.NoError(t, err)
+
+		_, err = DecryptDeterministic(encrypted, CreateKeyFromString("other-key"))
+		require.Error(t, err)
+
+		env, err := ParseEnvelope(encrypted)
+		require.NoError(t, err)
+		env.Ciphertext[0] ^= 0x01
+		_, err = DecryptDeterministic(env.Encode(), key)
+		require.Error(t, err)
+	})
+
+	t.Run("NotMixedWithRandomizedFormat", func(t *testing.T) {
+		deterministic, err := EncryptDeterministic("값", key)
+		require.NoError(t, err)
+		_, err = Decrypt(deterministic, key)
+		require.Error(t, err)
+
+		randomized, err := Encrypt("값", key)
+		require.NoError(t, err)
+		_, err = DecryptDeterministic(randomized, key)
+		require.Error(t, err)
+
+		// 접두사만 바꿔치기한 값도 거부
+		_, err = Decrypt(EnvelopePrefix+strings.TrimPrefix(deterministic, DeterministicPrefix), key)
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		_, err := EncryptDeterministic("", key)
+		require.Error(t, err)
+		_, err = EncryptDeterministic("값", []byte("short"))
+		require.Error(t, err)
+		_, err = DecryptDeterministic("", key)
+		require.Error(t, err)
+		_, err = DecryptDeterministic(DeterministicPrefix+"!!!", key)
+		require.Error(t, err)
+	})
+}
+
+// RFC 5297 부록 A.1 (AES-SIV-CMAC-256) 테스트 벡터
+func TestSIVKnownAnswer(t *testing.T) {
+	key := mustHex(t, "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	ad := mustHex(t, "101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := mustHex(t, "112233445566778899aabbccddee")
+
+	macBlock, err := aes.NewCipher(key[:16])
+	require.NoError(t, err)
+	ctrBlock, err := aes.NewCipher(key[16:])
+	require.NoError(t, err)
+	mac := newCMAC(macBlock)
+
+	siv, ciphertext := sivSeal(mac, ctrBlock, plaintext, ad)
+	require.Equal(t, "85632d07c6e8f37f950acd320a2ecc93", hex.EncodeToString(siv))
+	require.Equal(t, "40c02b9690c4dc04daef7f6afe5c", hex.EncodeToString(ciphertext))
+
+	opened, err := sivOpen(mac, ctrBlock, siv, ciphertext, ad)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, opened)
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
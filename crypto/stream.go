@@ -0,0 +1,417 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// 대용량 데이터용 스트리밍 암호화 (STREAM 방식의 청크 단위 AEAD)
+//
+//	header: "CMPS" | version(1) | keyIDLen(1) | keyID | algorithm(1) | chunkSize(4) | salt(16)
+//	chunk : AES-256-GCM(nonce = 0(7) | counter(4) | lastFlag(1), 평문 chunkSize 바이트) ...
+//
+// 스트림마다 HKDF-SHA256(key, salt, header) 로 새 키를 유도하므로 헤더가 바뀌면 복호화되지 않고,
+// 같은 키로 여러 스트림을 암호화해도 nonce 가 겹치지 않는다.
+// 청크 순서는 nonce 의 카운터로, 잘림은 마지막 청크 표시로 검출한다.
+
+// streamMagic 스트림 헤더 시작 바이트
+var streamMagic = []byte("CMPS")
+
+const (
+	// DefaultChunkSize 기본 청크 크기 (평문 기준)
+	DefaultChunkSize = 64 * 1024
+	// MaxChunkSize 허용하는 최대 청크 크기
+	MaxChunkSize = 16 * 1024 * 1024
+
+	streamSaltSize = 16
+)
+
+// StreamConfig 스트리밍 암호화 설정
+type StreamConfig struct {
+	ChunkSize int    // 청크 크기 (평문 기준)
+	KeyID     string // 헤더에 기록할 키 ID
+}
+
+// DefaultStreamConfig 기본 스트리밍 암호화 설정
+func DefaultStreamConfig() StreamConfig {
+	return StreamConfig{
+		ChunkSize: DefaultChunkSize,
+	}
+}
+
+// streamHeader 스트림 헤더
+type streamHeader struct {
+	Version   byte
+	KeyID     string
+	Algorithm Algorithm
+	ChunkSize int
+	Salt      []byte
+}
+
+func (h *streamHeader) bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(streamMagic)
+	buf.WriteByte(h.Version)
+	buf.WriteByte(byte(len(h.KeyID)))
+	buf.WriteString(h.KeyID)
+	buf.WriteByte(byte(h.Algorithm))
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(h.ChunkSize)))
+	buf.Write(h.Salt)
+	return buf.Bytes()
+}
+
+// readStreamHeader 스트림 헤더 읽기
+func readStreamHeader(r io.Reader) (*streamHeader, []byte, error) {
+	fixed := make([]byte, len(streamMagic)+2)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, nil, streamReadError(err, "스트림 헤더 읽기 실패")
+	}
+	if !bytes.Equal(fixed[:len(streamMagic)], streamMagic) {
+		return nil, nil, newError(ErrMalformedCiphertext, "암호화 스트림 형식이 아닙니다")
+	}
+
+	h := &streamHeader{Version: fixed[len(streamMagic)]}
+	if h.Version != EnvelopeVersion1 {
+		return nil, nil, newError(ErrMalformedCiphertext, "지원하지 않는 스트림 버전: %d", h.Version)
+	}
+
+	rest := make([]byte, int(fixed[len(streamMagic)+1])+1+4+streamSaltSize)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, nil, streamReadError(err, "스트림 헤더 읽기 실패")
+	}
+
+	keyIDLen := int(fixed[len(streamMagic)+1])
+	h.KeyID = string(rest[:keyIDLen])
+	h.Algorithm = Algorithm(rest[keyIDLen])
+	if h.Algorithm != AlgorithmAES256GCM {
+		return nil, nil, newError(ErrMalformedCiphertext, "지원하지 않는 알고리즘: %s", h.Algorithm)
+	}
+	h.ChunkSize = int(binary.BigEndian.Uint32(rest[keyIDLen+1:]))
+	if h.ChunkSize < 1 || h.ChunkSize > MaxChunkSize {
+		return nil, nil, newError(ErrMalformedCiphertext, "잘못된 청크 크기: %d", h.ChunkSize)
+	}
+	h.Salt = rest[keyIDLen+5:]
+
+	return h, append(fixed, rest...), nil
+}
+
+// streamReadError 헤더가 중간에 끊긴 경우는 형식 오류, 그 외는 읽기 오류로 감싼다
+func streamReadError(err error, msg string) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return wrapError(ErrMalformedCiphertext, err, "%s", msg)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// newStreamAEAD 스트림 키 유도 후 GCM 생성
+func newStreamAEAD(key []byte, salt []byte, header []byte) (cipher.AEAD, error) {
+	if err := checkKeySize(key); err != nil {
+		return nil, err
+	}
+
+	streamKey, err := hkdf.Key(sha256.New, key, salt, string(header), KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("스트림 키 유도 실패: %w", err)
+	}
+	return newGCM(streamKey)
+}
+
+// streamNonce 청크 nonce 생성
+func streamNonce(nonce []byte, counter uint32, final bool) []byte {
+	clear(nonce)
+	binary.BigEndian.PutUint32(nonce[len(nonce)-5:], counter)
+	if final {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// EncryptWriter 쓰는 데이터를 청크 단위로 암호화하여 하위 Writer 로 내보낸다
+//
+// 마지막 청크를 기록하기 위해 반드시 Close 를 호출해야 한다. Close 는 하위 Writer 를 닫지 않는다.
+type EncryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint32
+	buf     []byte
+	out     []byte
+	closed  bool
+	err     error
+}
+
+// NewEncryptWriter 헤더를 기록하고 EncryptWriter 생성
+func NewEncryptWriter(w io.Writer, key []byte, config StreamConfig) (*EncryptWriter, error) {
+	if config.ChunkSize < 1 || config.ChunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("청크 크기는 1 ~ %d 바이트여야 함: 현재 %d 바이트", MaxChunkSize, config.ChunkSize)
+	}
+	if err := checkKeyID(config.KeyID); err != nil {
+		return nil, err
+	}
+
+	h := &streamHeader{
+		Version:   EnvelopeVersion1,
+		KeyID:     config.KeyID,
+		Algorithm: AlgorithmAES256GCM,
+		ChunkSize: config.ChunkSize,
+		Salt:      make([]byte, streamSaltSize),
+	}
+	if _, err := io.ReadFull(randReader, h.Salt); err != nil {
+		return nil, fmt.Errorf("salt 생성 실패: %w", err)
+	}
+	header := h.bytes()
+
+	aead, err := newStreamAEAD(key, h.Salt, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(header); err != nil {
+		return nil, fmt.Errorf("스트림 헤더 쓰기 실패: %w", err)
+	}
+
+	return &EncryptWriter{
+		w:     w,
+		aead:  aead,
+		nonce: make([]byte, aead.NonceSize()),
+		buf:   make([]byte, 0, config.ChunkSize),
+		out:   make([]byte, 0, config.ChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Write 평문을 버퍼에 모으고 청크가 찰 때마다 암호화하여 기록
+func (e *EncryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("이미 닫힌 EncryptWriter 입니다")
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// 버퍼가 가득 찬 상태에서 데이터가 더 들어오면 마지막 청크가 아님
+		if len(e.buf) == cap(e.buf) {
+			if e.err = e.flush(false); e.err != nil {
+				return written, e.err
+			}
+		}
+
+		n := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close 남은 데이터를 마지막 청크로 암호화하여 기록
+func (e *EncryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.flush(true)
+	return e.err
+}
+
+func (e *EncryptWriter) flush(final bool) error {
+	if e.counter == math.MaxUint32 {
+		return errors.New("스트림 청크 개수 한도를 초과했습니다")
+	}
+
+	e.out = e.aead.Seal(e.out[:0], streamNonce(e.nonce, e.counter, final), e.buf, nil)
+	if _, err := e.w.Write(e.out); err != nil {
+		return fmt.Errorf("암호화 청크 쓰기 실패: %w", err)
+	}
+
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// DecryptReader 청크 단위로 암호화된 스트림을 읽어 복호화한다
+//
+// 변조, 청크 순서 변경, 잘림이 검출되면 Read 가 오류를 반환하며,
+// 그 전까지 반환된 데이터도 신뢰해서는 안 된다.
+type DecryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint32
+	enc     []byte
+	plain   []byte
+	pos     int
+	done    bool
+	err     error
+
+	// KeyID 헤더에 기록된 키 ID
+	KeyID string
+}
+
+// NewDecryptReader 헤더를 읽고 DecryptReader 생성
+func NewDecryptReader(r io.Reader, key []byte) (*DecryptReader, error) {
+	return NewDecryptReaderWithKeyLookup(r, func(string) ([]byte, error) {
+		return key, nil
+	})
+}
+
+// NewDecryptReaderWithKeyLookup 헤더의 키 ID 로 키를 찾아 DecryptReader 생성
+func NewDecryptReaderWithKeyLookup(r io.Reader, lookup KeyLookup) (*DecryptReader, error) {
+	h, header, err := readStreamHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := lookup(h.KeyID)
+	if err != nil {
+		return nil, &KeyIDError{KeyID: h.KeyID, Err: err}
+	}
+
+	aead, err := newStreamAEAD(key, h.Salt, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecryptReader{
+		r:     bufio.NewReaderSize(r, h.ChunkSize+aead.Overhead()+1),
+		aead:  aead,
+		nonce: make([]byte, aead.NonceSize()),
+		enc:   make([]byte, h.ChunkSize+aead.Overhead()),
+		KeyID: h.KeyID,
+	}, nil
+}
+
+// Read 복호화된 평문 읽기
+func (d *DecryptReader) Read(p []byte) (int, error) {
+	for d.pos == len(d.plain) {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.readChunk()
+	}
+
+	n := copy(p, d.plain[d.pos:])
+	d.pos += n
+	return n, nil
+}
+
+func (d *DecryptReader) readChunk() error {
+	n, err := io.ReadFull(d.r, d.enc)
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		// 청크 크기보다 짧으면 마지막 청크
+		final = true
+	case err != nil:
+		return fmt.Errorf("암호화 청크 읽기 실패: %w", err)
+	default:
+		// 뒤에 데이터가 없으면 마지막 청크
+		if _, err = d.r.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return fmt.Errorf("암호화 청크 읽기 실패: %w", err)
+		}
+	}
+
+	if n < d.aead.Overhead() {
+		return newError(ErrMalformedCiphertext, "암호화 스트림이 잘렸습니다")
+	}
+	if !final && d.counter == math.MaxUint32 {
+		return newError(ErrMalformedCiphertext, "스트림 청크 개수 한도를 초과했습니다")
+	}
+
+	plain, err := d.aead.Open(d.plain[:0], streamNonce(d.nonce, d.counter, final), d.enc[:n], nil)
+	if err != nil {
+		return wrapError(ErrAuthenticationFailed, err, "청크 %d 복호화 실패 (키가 올바르지 않거나 스트림이 변조/잘림)", d.counter)
+	}
+
+	d.plain, d.pos = plain, 0
+	d.counter++
+	d.done = final
+	return nil
+}
+
+// EncryptFile 파일을 스트리밍 방식으로 암호화하여 dst 에 저장
+func EncryptFile(src, dst string, key []byte, config StreamConfig) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("원본 파일 열기 실패: %w", err)
+	}
+	defer in.Close()
+
+	return writeFileAtomic(dst, func(out io.Writer) error {
+		w, err := NewEncryptWriter(out, key, config)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(w, in); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+}
+
+// DecryptFile 스트리밍 방식으로 암호화된 파일을 복호화하여 dst 에 저장
+//
+// 복호화가 끝까지 성공한 경우에만 dst 가 생성된다.
+func DecryptFile(src, dst string, key []byte) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("암호화 파일 열기 실패: %w", err)
+	}
+	defer in.Close()
+
+	return writeFileAtomic(dst, func(out io.Writer) error {
+		r, err := NewDecryptReader(in, key)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, r)
+		return err
+	})
+}
+
+// writeFileAtomic 같은 디렉토리의 임시 파일에 기록한 뒤 성공하면 dst 로 이름을 바꾼다
+func writeFileAtomic(dst string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("임시 파일 생성 실패: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	out := bufio.NewWriter(tmp)
+	if err = write(out); err == nil {
+		err = out.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("파일 이름 변경 실패: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encryptStream(t *testing.T, plain []byte, key []byte, config StreamConfig) []byte {
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key, config)
+	require.NoError(t, err)
+
+	// 청크 경계와 어긋나게 나눠서 쓰기
+	for len(plain) > 0 {
+		n := min(len(plain), 37)
+		_, err = w.Write(plain[:n])
+		require.NoError(t, err)
+		plain = plain[n:]
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func decryptStream(key []byte, encrypted []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(encrypted), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStream(t *testing.T) {
+	key := CreateKeyFromString("stream-test-key")
+	config := StreamConfig{ChunkSize: 64, KeyID: "k1"}
+	headerSize := len(streamMagic) + 3 + len(config.KeyID) + 4 + streamSaltSize
+	encChunk := config.ChunkSize + 16
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, size := range []int{0, 1, 63, 64, 65, 128, 1000} {
+			plain := make([]byte, size)
+			_, err := rand.Read(plain)
+			require.NoError(t, err)
+
+			encrypted := encryptStream(t, plain, key, config)
+			decrypted, err := decryptStream(key, encrypted)
+			require.NoError(t, err, "size %d", size)
+			require.Equal(t, plain, append([]byte{}, decrypted...), "size %d", size)
+		}
+	})
+
+	t.Run("KeyLookup", func(t *testing.T) {
+		encrypted := encryptStream(t, []byte("kubeconfig"), key, config)
+		r, err := NewDecryptReaderWithKeyLookup(bytes.NewReader(encrypted), func(keyID string) ([]byte, error) {
+			require.Equal(t, "k1", keyID)
+			return key, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "k1", r.KeyID)
+		decrypted, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "kubeconfig", string(decrypted))
+
+		// 키 조회 실패는 봉투 복호화와 같이 KeyIDError
+		_, err = NewDecryptReaderWithKeyLookup(bytes.NewReader(encrypted), func(string) ([]byte, error) {
+			return nil, errors.New("not found")
+		})
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+		var keyIDErr *KeyIDError
+		require.ErrorAs(t, err, &keyIDErr)
+		require.Equal(t, "k1", keyIDErr.KeyID)
+	})
+
+	plain := bytes.Repeat([]byte("0123456789abcdef"), 12) // 192 바이트 = 청크 3개, 세번째가 마지막 청크
+	encrypted := encryptStream(t, plain, key, config)
+	require.Len(t, encrypted, headerSize+3*encChunk)
+
+	t.Run("Truncated", func(t *testing.T) {
+		for _, cut := range []int{headerSize + encChunk, headerSize + 2*encChunk, len(encrypted) - 1, headerSize - 1} {
+			_, err := decryptStream(key, encrypted[:cut])
+			require.Error(t, err, "cut %d", cut)
+		}
+	})
+
+	t.Run("Reordered", func(t *testing.T) {
+		swapped := append([]byte{}, encrypted[:headerSize]...)
+		swapped = append(swapped, encrypted[headerSize+encChunk:headerSize+2*encChunk]...)
+		swapped = append(swapped, encrypted[headerSize:headerSize+encChunk]...)
+		swapped = append(swapped, encrypted[headerSize+2*encChunk:]...)
+		_, err := decryptStream(key, swapped)
+		require.Error(t, err)
+	})
+
+	t.Run("Appended", func(t *testing.T) {
+		_, err := decryptStream(key, append(append([]byte{}, encrypted...), encrypted[headerSize:headerSize+encChunk]...))
+		require.Error(t, err)
+	})
+
+	t.Run("TamperedHeader", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[headerSize-1] ^= 1
+		_, err := decryptStream(key, tampered)
+		require.Error(t, err)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		_, err := decryptStream(CreateKeyFromString("other"), encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		_, err := NewEncryptWriter(io.Discard, key, StreamConfig{})
+		require.Error(t, err)
+		_, err = NewEncryptWriter(io.Discard, []byte("short"), DefaultStreamConfig())
+		require.Error(t, err)
+		_, err = NewDecryptReader(bytes.NewReader([]byte("not a stream")), key)
+		require.Error(t, err)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "inventory.json")
+		enc := filepath.Join(dir, "inventory.json.enc")
+		dst := filepath.Join(dir, "inventory.out.json")
+
+		data := bytes.Repeat([]byte(`{"vm":"인스턴스"}`), 10000)
+		require.NoError(t, os.WriteFile(src, data, 0600))
+
+		require.NoError(t, EncryptFile(src, enc, key, DefaultStreamConfig()))
+		require.NoError(t, DecryptFile(enc, dst, key))
+
+		decrypted, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		require.Equal(t, data, decrypted)
+
+		// 복호화 실패 시 결과 파일이 만들어지지 않아야 함
+		failed := filepath.Join(dir, "failed.json")
+		require.Error(t, DecryptFile(enc, failed, CreateKeyFromString("other")))
+		_, err = os.Stat(failed)
+		require.True(t, os.IsNotExist(err))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+	})
+}
@@ -0,0 +1,264 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 구조체 필드 암호화
+//
+//	type ProviderConfig struct {
+//		Name      string
+//		AccessKey string            `cmp:"secret"`
+//		Tokens    map[string]string `cmp:"secret"`
+//		Accounts  []Account         // 중첩 구조체의 태그도 따라간다
+//	}
+//
+// `cmp:"secret"` 태그는 string 과 그 포인터, 슬라이스, 배열, 맵 값에 붙일 수 있다.
+// 태그가 없는 필드는 구조체, 포인터, 인터페이스, 슬라이스, 배열, 맵을 따라 내려가며 태그된 필드를 찾는다.
+// 빈 문자열과 nil 은 그대로 둔다.
+// 모든 대상 필드를 모아 대량 암복호화 엔진으로 한 번에 처리하며, 하나라도 실패하면 값을 바꾸지 않고 오류를 반환한다.
+
+// SecretTagKey, SecretTagValue 암호화 대상 필드에 붙이는 태그 키와 값
+const (
+	SecretTagKey   = "cmp"
+	SecretTagValue = "secret"
+)
+
+// EncryptStruct 구조체 포인터의 `cmp:"secret"` 필드를 암호화
+func EncryptStruct(v any, key []byte) error {
+	c, err := NewCipher(key)
+	if err != nil {
+		return err
+	}
+	return c.EncryptStruct(context.Background(), v, DefaultConcurrencyLimit)
+}
+
+// DecryptStruct 구조체 포인터의 `cmp:"secret"` 필드를 복호화
+func DecryptStruct(v any, key []byte) error {
+	c, err := NewCipher(key)
+	if err != nil {
+		return err
+	}
+	return c.DecryptStruct(context.Background(), v, DefaultConcurrencyLimit)
+}
+
+// EncryptStruct 구조체 포인터의 `cmp:"secret"` 필드를 취소 가능한 동시성 제한으로 암호화
+func (c *Cipher) EncryptStruct(ctx context.Context, v any, concurrencyLimit int) error {
+	fields, err := collectSecretFields(v)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.bulkEncrypt(ctx, fields.values(), nil, concurrencyLimit)
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]string, len(results))
+	errs := make([]error, len(results))
+	for i, r := range results {
+		outputs[i], errs[i] = r.Encrypted, r.Error
+	}
+	return fields.apply(outputs, errs)
+}
+
+// DecryptStruct 구조체 포인터의 `cmp:"secret"` 필드를 취소 가능한 동시성 제한으로 복호화
+func (c *Cipher) DecryptStruct(ctx context.Context, v any, concurrencyLimit int) error {
+	fields, err := collectSecretFields(v)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.bulkDecrypt(ctx, fields.values(), nil, concurrencyLimit)
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]string, len(results))
+	errs := make([]error, len(results))
+	for i, r := range results {
+		outputs[i], errs[i] = r.Decrypted, r.Error
+	}
+	return fields.apply(outputs, errs)
+}
+
+// secretField 암복호화 대상 문자열 하나
+type secretField struct {
+	path  string
+	value reflect.Value
+}
+
+// secretFields 구조체에서 모은 대상 필드와, 값을 바꾼 뒤 맵에 다시 넣는 작업
+type secretFields struct {
+	fields  []secretField
+	commits []func()
+}
+
+func (s *secretFields) values() []string {
+	values := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		values[i] = f.value.String()
+	}
+	return values
+}
+
+// apply 모두 성공한 경우에만 결과를 필드에 쓴다
+func (s *secretFields) apply(outputs []string, errs []error) error {
+	var joined []error
+	for i, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("%s: %w", s.fields[i].path, err))
+		}
+	}
+	if len(joined) > 0 {
+		return errors.Join(joined...)
+	}
+
+	for i, f := range s.fields {
+		f.value.SetString(outputs[i])
+	}
+	// 안쪽 맵과 인터페이스부터 등록되므로 등록 순서대로 다시 넣는다
+	for _, commit := range s.commits {
+		commit()
+	}
+	return nil
+}
+
+// collectSecretFields 구조체 포인터에서 `cmp:"secret"` 필드를 모은다
+func collectSecretFields(v any) (*secretFields, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, errors.New("구조체 포인터가 필요합니다")
+	}
+
+	w := &structWalker{fields: &secretFields{}, visited: make(map[visit]bool)}
+	if err := w.walk(rv, rv.Elem().Type().Name(), false); err != nil {
+		return nil, err
+	}
+	return w.fields, nil
+}
+
+type visit struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+type structWalker struct {
+	fields  *secretFields
+	visited map[visit]bool
+}
+
+// walk 값을 따라 내려가며 대상 필드를 모은다 (secret 이면 문자열을 대상으로 등록)
+func (w *structWalker) walk(v reflect.Value, path string, secret bool) error {
+	switch v.Kind() {
+	case reflect.String:
+		if secret && v.Len() > 0 {
+			w.fields.fields = append(w.fields.fields, secretField{path: path, value: v})
+		}
+		return nil
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		// 순환 참조 방지
+		key := visit{typ: v.Type(), ptr: v.Pointer()}
+		if w.visited[key] {
+			return nil
+		}
+		w.visited[key] = true
+		return w.walk(v.Elem(), path, secret)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.Pointer {
+			return w.walk(elem, path, secret)
+		}
+		// 인터페이스 안의 값은 주소를 얻을 수 없으므로 복사본을 수정한 뒤 다시 넣는다
+		copied := reflect.New(elem.Type()).Elem()
+		copied.Set(elem)
+
+		before := len(w.fields.fields)
+		if err := w.walk(copied, path, secret); err != nil {
+			return err
+		}
+		if len(w.fields.fields) > before {
+			if !v.CanSet() {
+				return fmt.Errorf("%s: 인터페이스 안의 값을 수정할 수 없습니다", path)
+			}
+			w.fields.commits = append(w.fields.commits, func() { v.Set(copied) })
+		}
+		return nil
+
+	case reflect.Struct:
+		if secret {
+			return fmt.Errorf("%s: `cmp:\"secret\"` 태그는 문자열 필드에만 사용할 수 있습니다", path)
+		}
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			fieldSecret := isSecretTag(field.Tag)
+			if !field.IsExported() {
+				if fieldSecret {
+					return fmt.Errorf("%s.%s: 비공개 필드는 암호화할 수 없습니다", path, field.Name)
+				}
+				continue
+			}
+			if err := w.walk(v.Field(i), path+"."+field.Name, fieldSecret); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), secret); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			// 맵 값은 주소를 얻을 수 없으므로 복사본을 수정한 뒤 다시 넣는다
+			mapKey := iter.Key()
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+
+			before := len(w.fields.fields)
+			if err := w.walk(elem, fmt.Sprintf("%s[%v]", path, mapKey), secret); err != nil {
+				return err
+			}
+			if len(w.fields.fields) > before {
+				w.fields.commits = append(w.fields.commits, func() { v.SetMapIndex(mapKey, elem) })
+			}
+		}
+		return nil
+
+	default:
+		if secret {
+			return fmt.Errorf("%s: `cmp:\"secret\"` 태그는 문자열 필드에만 사용할 수 있습니다 (%s)", path, v.Type())
+		}
+		return nil
+	}
+}
+
+// isSecretTag `cmp:"secret"` 태그 여부 (쉼표 뒤 옵션은 무시)
+func isSecretTag(tag reflect.StructTag) bool {
+	value, _, _ := strings.Cut(tag.Get(SecretTagKey), ",")
+	return value == SecretTagValue
+}
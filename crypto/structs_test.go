@@ -0,0 +1,165 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAccount struct {
+	Name     string
+	Password string  `cmp:"secret"`
+	Token    *string `cmp:"secret,omitempty"`
+}
+
+type testProviderConfig struct {
+	Provider  string
+	AccessKey string            `cmp:"secret"`
+	Tokens    map[string]string `cmp:"secret"`
+	Backups   []string          `cmp:"secret"`
+	Accounts  []testAccount
+	ByRegion  map[string]testAccount
+	Primary   *testAccount
+	Shared    *testAccount
+	Extra     any
+	unrelated string
+}
+
+func TestStructEncryption(t *testing.T) {
+	key := CreateKeyFromString("struct-test-key")
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		token := "account-token"
+		shared := &testAccount{Name: "shared", Password: "shared-pw"}
+		config := &testProviderConfig{
+			Provider:  "aws",
+			AccessKey: "AKIAEXAMPLE",
+			Tokens:    map[string]string{"a": "token-a", "b": ""},
+			Backups:   []string{"backup-1", "backup-2"},
+			Accounts:  []testAccount{{Name: "first", Password: "pw-1", Token: &token}, {Name: "second"}},
+			ByRegion:  map[string]testAccount{"kr": {Name: "kr", Password: "pw-kr"}},
+			Primary:   shared,
+			Shared:    shared,
+			Extra:     &testAccount{Password: "extra-pw"},
+			unrelated: "plain",
+		}
+
+		require.NoError(t, EncryptStruct(config, key))
+
+		require.Equal(t, "aws", config.Provider)
+		require.Equal(t, "first", config.Accounts[0].Name)
+		require.Equal(t, "plain", config.unrelated)
+		require.True(t, IsEnvelope(config.AccessKey))
+		require.True(t, IsEnvelope(config.Tokens["a"]))
+		require.Equal(t, "", config.Tokens["b"])
+		require.True(t, IsEnvelope(config.Backups[1]))
+		require.True(t, IsEnvelope(config.Accounts[0].Password))
+		require.True(t, IsEnvelope(*config.Accounts[0].Token))
+		require.Equal(t, "", config.Accounts[1].Password)
+		require.True(t, IsEnvelope(config.ByRegion["kr"].Password))
+		require.True(t, IsEnvelope(config.Extra.(*testAccount).Password))
+
+		// 같은 포인터를 두 번 암호화하지 않음
+		decrypted, err := Decrypt(config.Shared.Password, key)
+		require.NoError(t, err)
+		require.Equal(t, "shared-pw", decrypted)
+
+		require.NoError(t, DecryptStruct(config, key))
+
+		require.Equal(t, "AKIAEXAMPLE", config.AccessKey)
+		require.Equal(t, map[string]string{"a": "token-a", "b": ""}, config.Tokens)
+		require.Equal(t, []string{"backup-1", "backup-2"}, config.Backups)
+		require.Equal(t, "pw-1", config.Accounts[0].Password)
+		require.Equal(t, "account-token", *config.Accounts[0].Token)
+		require.Equal(t, "pw-kr", config.ByRegion["kr"].Password)
+		require.Equal(t, "shared-pw", config.Primary.Password)
+		require.Equal(t, "extra-pw", config.Extra.(*testAccount).Password)
+	})
+
+	t.Run("InterfaceValues", func(t *testing.T) {
+		holder := &struct {
+			Items map[string]any
+			One   any
+			Token any `cmp:"secret"`
+		}{
+			Items: map[string]any{
+				"account": testAccount{Name: "in-map", Password: "map-pw"},
+				"plain":   "not-secret",
+			},
+			One:   testAccount{Password: "one-pw"},
+			Token: "token",
+		}
+
+		require.NoError(t, EncryptStruct(holder, key))
+		require.True(t, IsEnvelope(holder.Items["account"].(testAccount).Password))
+		require.Equal(t, "in-map", holder.Items["account"].(testAccount).Name)
+		require.Equal(t, "not-secret", holder.Items["plain"])
+		require.True(t, IsEnvelope(holder.One.(testAccount).Password))
+		require.True(t, IsEnvelope(holder.Token.(string)))
+
+		require.NoError(t, DecryptStruct(holder, key))
+		require.Equal(t, "map-pw", holder.Items["account"].(testAccount).Password)
+		require.Equal(t, "one-pw", holder.One.(testAccount).Password)
+		require.Equal(t, "token", holder.Token)
+	})
+
+	t.Run("AllOrNothing", func(t *testing.T) {
+		encrypted, err := Encrypt("pw", key)
+		require.NoError(t, err)
+
+		accounts := &struct{ Accounts []testAccount }{
+			Accounts: []testAccount{{Password: encrypted}, {Password: "not-encrypted"}},
+		}
+		err = DecryptStruct(accounts, key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Accounts[1].Password")
+		require.Equal(t, encrypted, accounts.Accounts[0].Password)
+	})
+
+	t.Run("InvalidTarget", func(t *testing.T) {
+		require.Error(t, EncryptStruct(testAccount{}, key))
+		require.Error(t, EncryptStruct((*testAccount)(nil), key))
+
+		type badTag struct {
+			Port int `cmp:"secret"`
+		}
+		require.Error(t, EncryptStruct(&badTag{Port: 1}, key))
+
+		type unexportedSecret struct {
+			password string `cmp:"secret"`
+		}
+		require.Error(t, EncryptStruct(&unexportedSecret{password: "pw"}, key))
+
+		require.Error(t, EncryptStruct(&testAccount{Password: "pw"}, []byte("short")))
+	})
+
+	t.Run("LargeBatch", func(t *testing.T) {
+		c, err := NewCipher(key)
+		require.NoError(t, err)
+
+		batch := &struct{ Accounts []testAccount }{Accounts: make([]testAccount, 1000)}
+		for i := range batch.Accounts {
+			batch.Accounts[i].Password = fmt.Sprintf("pw-%d", i)
+		}
+
+		require.NoError(t, c.EncryptStruct(context.Background(), batch, 8))
+		require.NoError(t, c.DecryptStruct(context.Background(), batch, 8))
+		for i, account := range batch.Accounts {
+			require.Equal(t, fmt.Sprintf("pw-%d", i), account.Password)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		c, err := NewCipher(key)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		account := &testAccount{Password: "pw"}
+		require.ErrorIs(t, c.EncryptStruct(ctx, account, 1), context.Canceled)
+		require.Equal(t, "pw", account.Password)
+	})
+}
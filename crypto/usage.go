@@ -0,0 +1,178 @@
+package crypto
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hsjahng/cmp-common/logger"
+	"go.uber.org/zap"
+)
+
+// 키 사용량 집계
+//
+// 12바이트 랜덤 nonce 를 쓰는 AEAD(AES-256-GCM, ChaCha20-Poly1305)는 nonce 충돌 확률 때문에
+// 키 하나로 약 2^32 건까지만 암호화해야 한다 (NIST SP 800-38D). 대량 재암호화 작업은 이 한도에 금방 닿을 수 있으므로
+// UsageTracker 를 Cipher 나 Keyring 에 연결해 키 ID 별 암호화 횟수를 세고, 경고 기준에 도달하면 logger 로 경고하며,
+// 한도에 도달하면 ErrKeyUsageExceeded 로 암호화를 거부한다.
+//
+// 집계는 키 ID 별로 하므로 키마다 다른 키 ID 를 붙여야 한다. 메모리에만 보관하므로
+// 프로세스를 다시 시작해도 이어지도록 Snapshot 을 저장해 두었다가 Restore 로 되살린다.
+// 24바이트 nonce 를 쓰는 XChaCha20-Poly1305 는 횟수만 세고 경고나 한도를 적용하지 않는다.
+
+const (
+	// DefaultUsageLimit 12바이트 랜덤 nonce 알고리즘의 키당 암호화 한도 (2^32)
+	DefaultUsageLimit uint64 = 1 << 32
+	// DefaultUsageWarnThreshold 기본 경고 기준 (한도의 75%)
+	DefaultUsageWarnThreshold = DefaultUsageLimit / 4 * 3
+)
+
+// UsageConfig 키 사용량 경고, 한도 설정
+type UsageConfig struct {
+	WarnThreshold uint64             // 이 횟수에 도달하면 키마다 한 번 경고 (0 이면 경고하지 않음)
+	Limit         uint64             // 키당 최대 암호화 횟수 (0 이면 한도 없음)
+	Enforce       bool               // true 면 한도를 넘는 암호화를 거부, false 면 오류 로그만 남기고 암호화
+	Logger        *zap.SugaredLogger // nil 이면 경고할 때 logger.GetSugaredLogger() 를 조회
+}
+
+// DefaultUsageConfig 기본 설정 (2^32 회 한도, 75% 에서 경고, 한도 도달 시 거부)
+func DefaultUsageConfig() UsageConfig {
+	return UsageConfig{
+		WarnThreshold: DefaultUsageWarnThreshold,
+		Limit:         DefaultUsageLimit,
+		Enforce:       true,
+	}
+}
+
+// KeyUsage 키 하나의 사용량
+type KeyUsage struct {
+	KeyID       string `json:"keyId"`
+	Encryptions uint64 `json:"encryptions"` // 암호화 횟수
+	Refused     uint64 `json:"refused"`     // 한도 초과로 거부한 횟수
+}
+
+// UsageTracker 키 ID 별 암호화 횟수 집계, 여러 고루틴에서 동시에 사용해도 안전하다
+type UsageTracker struct {
+	config UsageConfig
+
+	mu   sync.RWMutex
+	keys map[string]*keyCounter
+}
+
+type keyCounter struct {
+	encryptions atomic.Uint64
+	refused     atomic.Uint64
+	warned      atomic.Bool
+	exhausted   atomic.Bool
+}
+
+// NewUsageTracker 설정으로 UsageTracker 생성
+func NewUsageTracker(config UsageConfig) *UsageTracker {
+	return &UsageTracker{config: config, keys: make(map[string]*keyCounter)}
+}
+
+// logger 경고를 남길 logger, 앱 logger 가 tracker 보다 늦게 초기화될 수 있으므로 쓸 때마다 조회한다
+func (t *UsageTracker) logger() *zap.SugaredLogger {
+	if t.config.Logger != nil {
+		return t.config.Logger
+	}
+	if log := logger.GetSugaredLogger(); log != nil {
+		return log
+	}
+	return zap.NewNop().Sugar()
+}
+
+func (t *UsageTracker) counter(keyID string) *keyCounter {
+	t.mu.RLock()
+	c, ok := t.keys[keyID]
+	t.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c, ok = t.keys[keyID]; !ok {
+		c = &keyCounter{}
+		t.keys[keyID] = c
+	}
+	return c
+}
+
+// reserve 암호화 한 번을 집계, 한도를 넘으면 ErrKeyUsageExceeded
+func (t *UsageTracker) reserve(keyID string, algorithm Algorithm) error {
+	c := t.counter(keyID)
+	n := c.encryptions.Add(1)
+	if algorithm.NonceSize() >= AlgorithmXChaCha20Poly1305.NonceSize() {
+		return nil
+	}
+
+	if t.config.Limit > 0 && n > t.config.Limit {
+		first := c.exhausted.CompareAndSwap(false, true)
+		if t.config.Enforce {
+			c.encryptions.Add(^uint64(0))
+			c.refused.Add(1)
+			if first {
+				t.logger().Errorw("키 사용 한도에 도달해 암호화를 거부합니다. 키를 교체하세요",
+					"keyId", keyID, "algorithm", algorithm.String(), "limit", t.config.Limit)
+			}
+			return newError(ErrKeyUsageExceeded, "키 %q 의 암호화 횟수가 한도 %d 회에 도달했습니다", keyID, t.config.Limit)
+		}
+		if first {
+			t.logger().Errorw("키 사용 한도를 넘었습니다. 키를 교체하세요",
+				"keyId", keyID, "algorithm", algorithm.String(), "limit", t.config.Limit)
+		}
+	}
+
+	if t.config.WarnThreshold > 0 && n >= t.config.WarnThreshold && c.warned.CompareAndSwap(false, true) {
+		t.logger().Warnw("키 사용량이 경고 기준에 도달했습니다. 키 교체를 준비하세요",
+			"keyId", keyID, "algorithm", algorithm.String(), "encryptions", n, "limit", t.config.Limit)
+	}
+	return nil
+}
+
+// Usage 키 ID 의 현재 사용량
+func (t *UsageTracker) Usage(keyID string) KeyUsage {
+	t.mu.RLock()
+	c, ok := t.keys[keyID]
+	t.mu.RUnlock()
+	if !ok {
+		return KeyUsage{KeyID: keyID}
+	}
+	return KeyUsage{KeyID: keyID, Encryptions: c.encryptions.Load(), Refused: c.refused.Load()}
+}
+
+// Snapshot 모든 키의 사용량 (키 ID 순), JSON 등으로 저장해 두었다가 Restore 에 넘긴다
+func (t *UsageTracker) Snapshot() []KeyUsage {
+	t.mu.RLock()
+	ids := make([]string, 0, len(t.keys))
+	for id := range t.keys {
+		ids = append(ids, id)
+	}
+	t.mu.RUnlock()
+	sort.Strings(ids)
+
+	usages := make([]KeyUsage, len(ids))
+	for i, id := range ids {
+		usages[i] = t.Usage(id)
+	}
+	return usages
+}
+
+// Restore 저장해 둔 사용량을 되살림, 이미 집계된 값이 더 크면 그대로 둔다
+func (t *UsageTracker) Restore(usages []KeyUsage) {
+	for _, u := range usages {
+		c := t.counter(u.KeyID)
+		storeMax(&c.encryptions, u.Encryptions)
+		storeMax(&c.refused, u.Refused)
+	}
+}
+
+func storeMax(v *atomic.Uint64, n uint64) {
+	for {
+		current := v.Load()
+		if current >= n || v.CompareAndSwap(current, n) {
+			return
+		}
+	}
+}
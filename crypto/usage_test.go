@@ -0,0 +1,156 @@
+package crypto
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestUsageTracker(warn, limit uint64, enforce bool) (*UsageTracker, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	return NewUsageTracker(UsageConfig{
+		WarnThreshold: warn,
+		Limit:         limit,
+		Enforce:       enforce,
+		Logger:        zap.New(core).Sugar(),
+	}), logs
+}
+
+func TestUsageTracker(t *testing.T) {
+	key := CreateKeyFromString("usage-test-key")
+
+	t.Run("WarnAndRefuse", func(t *testing.T) {
+		tracker, logs := newTestUsageTracker(3, 5, true)
+		c, err := NewCipherWithConfig(key, CipherConfig{KeyID: "k1", Algorithm: AlgorithmAES256GCM, Usage: tracker})
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err = c.Encrypt("값")
+			require.NoError(t, err)
+		}
+		warnings := logs.FilterLevelExact(zapcore.WarnLevel).All()
+		require.Len(t, warnings, 1)
+		require.Equal(t, "k1", warnings[0].ContextMap()["keyId"])
+
+		_, err = c.Encrypt("값")
+		require.ErrorIs(t, err, ErrKeyUsageExceeded)
+		_, err = c.Encrypt("값")
+		require.ErrorIs(t, err, ErrKeyUsageExceeded)
+		require.Len(t, logs.FilterLevelExact(zapcore.ErrorLevel).All(), 1)
+
+		require.Equal(t, KeyUsage{KeyID: "k1", Encryptions: 5, Refused: 2}, tracker.Usage("k1"))
+
+		// 복호화는 집계하지 않음
+		encrypted, err := NewCipherWithKeyID(key, "k1")
+		require.NoError(t, err)
+		text, err := encrypted.Encrypt("값")
+		require.NoError(t, err)
+		_, err = c.Decrypt(text)
+		require.NoError(t, err)
+	})
+
+	t.Run("NotEnforced", func(t *testing.T) {
+		tracker, logs := newTestUsageTracker(0, 2, false)
+		c, err := NewCipherWithConfig(key, CipherConfig{KeyID: "k1", Algorithm: AlgorithmAES256GCM, Usage: tracker})
+		require.NoError(t, err)
+
+		for i := 0; i < 4; i++ {
+			_, err = c.Encrypt("값")
+			require.NoError(t, err)
+		}
+		require.Equal(t, uint64(4), tracker.Usage("k1").Encryptions)
+		require.Len(t, logs.FilterLevelExact(zapcore.ErrorLevel).All(), 1)
+	})
+
+	t.Run("ExtendedNonceExempt", func(t *testing.T) {
+		tracker, logs := newTestUsageTracker(1, 1, true)
+		c, err := NewCipherWithConfig(key, CipherConfig{KeyID: "x1", Algorithm: AlgorithmXChaCha20Poly1305, Usage: tracker})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err = c.Encrypt("값")
+			require.NoError(t, err)
+		}
+		require.Equal(t, uint64(3), tracker.Usage("x1").Encryptions)
+		require.Zero(t, logs.Len())
+	})
+
+	t.Run("BulkEncrypt", func(t *testing.T) {
+		tracker, _ := newTestUsageTracker(0, 10, true)
+		c, err := NewCipherWithConfig(key, CipherConfig{KeyID: "k1", Algorithm: AlgorithmAES256GCM, Usage: tracker})
+		require.NoError(t, err)
+
+		contents := make([]string, 15)
+		for i := range contents {
+			contents[i] = "값"
+		}
+		results, err := c.BulkEncrypt(context.Background(), contents, 4)
+		require.NoError(t, err)
+
+		refused := 0
+		for _, r := range results {
+			if r.Error != nil {
+				require.ErrorIs(t, r.Error, ErrKeyUsageExceeded)
+				refused++
+			}
+		}
+		require.Equal(t, 5, refused)
+		require.Equal(t, KeyUsage{KeyID: "k1", Encryptions: 10, Refused: 5}, tracker.Usage("k1"))
+	})
+
+	t.Run("Keyring", func(t *testing.T) {
+		tracker, _ := newTestUsageTracker(0, 2, true)
+		ring, err := NewKeyring("v1", key)
+		require.NoError(t, err)
+		ring.TrackUsage(tracker)
+
+		for i := 0; i < 2; i++ {
+			_, err = ring.Encrypt("값")
+			require.NoError(t, err)
+		}
+		_, err = ring.Encrypt("값")
+		require.ErrorIs(t, err, ErrKeyUsageExceeded)
+
+		// 키를 교체하면 새 키로 다시 암호화할 수 있음
+		require.NoError(t, ring.Rotate("v2", CreateKeyFromString("v2")))
+		encrypted, err := ring.Encrypt("값")
+		require.NoError(t, err)
+		decrypted, err := ring.Decrypt(encrypted)
+		require.NoError(t, err)
+		require.Equal(t, "값", decrypted)
+
+		require.Equal(t, []KeyUsage{
+			{KeyID: "v1", Encryptions: 2, Refused: 1},
+			{KeyID: "v2", Encryptions: 1},
+		}, tracker.Snapshot())
+	})
+
+	t.Run("SnapshotRestore", func(t *testing.T) {
+		tracker, logs := newTestUsageTracker(4, 5, true)
+		tracker.Restore([]KeyUsage{{KeyID: "k1", Encryptions: 4}})
+
+		c, err := NewCipherWithConfig(key, CipherConfig{KeyID: "k1", Algorithm: AlgorithmAES256GCM, Usage: tracker})
+		require.NoError(t, err)
+		_, err = c.Encrypt("값")
+		require.NoError(t, err)
+		require.Len(t, logs.FilterLevelExact(zapcore.WarnLevel).All(), 1)
+		_, err = c.Encrypt("값")
+		require.ErrorIs(t, err, ErrKeyUsageExceeded)
+
+		encoded, err := json.Marshal(tracker.Snapshot())
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"keyId":"k1","encryptions":5,"refused":1}]`, string(encoded))
+
+		var saved []KeyUsage
+		require.NoError(t, json.Unmarshal(encoded, &saved))
+		restored := NewUsageTracker(DefaultUsageConfig())
+		restored.Restore(saved)
+		restored.Restore([]KeyUsage{{KeyID: "k1", Encryptions: 1}})
+		require.Equal(t, saved, restored.Snapshot())
+	})
+}
@@ -0,0 +1,211 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// 알려진 답(known-answer) 벡터와 호환성 고정 암호문
+//
+// testdata/vectors.json 은 난수원을 고정한 채 암호화한 결과로, 같은 입력이면 같은 암호문이 나와야 한다.
+// testdata/compat.json 은 현재 형식으로 실제 암호화해 둔 암호문으로, 복호화만 확인한다.
+// 두 파일은 이미 저장된 데이터와의 호환성을 지키기 위한 것이므로 형식을 바꾸더라도 수정하지 말고 항목을 추가만 한다.
+
+// testVector 벡터 한 건
+type testVector struct {
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"` // envelope, legacy, kek, deterministic, hybrid, stream
+	Key        string    `json:"key"`  // hex (kek: KEK 키, hybrid: 수신자 X25519 개인 키)
+	KeyID      string    `json:"keyId,omitempty"`
+	Algorithm  Algorithm `json:"algorithm,omitempty"`
+	AAD        string    `json:"aad,omitempty"`
+	ChunkSize  int       `json:"chunkSize,omitempty"` // stream
+	Random     string    `json:"random,omitempty"`    // hex, 암호화할 때 난수원이 차례로 돌려줄 바이트
+	Plaintext  string    `json:"plaintext"`
+	Ciphertext string    `json:"ciphertext"` // stream 은 base64
+}
+
+func loadTestVectors(t *testing.T, name string) []testVector {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	var vectors []testVector
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	require.NotEmpty(t, vectors)
+	return vectors
+}
+
+func (v *testVector) key(t *testing.T) []byte {
+	t.Helper()
+	key, err := hex.DecodeString(v.Key)
+	require.NoError(t, err)
+	return key
+}
+
+func (v *testVector) aad() []byte {
+	if len(v.AAD) == 0 {
+		return nil
+	}
+	return []byte(v.AAD)
+}
+
+// withRandom 난수원을 random 으로 고정하고 encrypt 실행, random 을 정확히 다 써야 한다
+func withRandom(t *testing.T, random string, encrypt func() (string, error)) (string, error) {
+	t.Helper()
+	raw, err := hex.DecodeString(random)
+	require.NoError(t, err)
+
+	reader := bytes.NewReader(raw)
+	defer func(original io.Reader) { randReader = original }(randReader)
+	randReader = reader
+
+	encrypted, err := encrypt()
+	require.Zero(t, reader.Len(), "고정 난수를 다 쓰지 않음")
+	return encrypted, err
+}
+
+func encryptVector(t *testing.T, v testVector) (string, error) {
+	t.Helper()
+	key := v.key(t)
+
+	switch v.Kind {
+	case "envelope":
+		c, err := NewCipherWithConfig(key, CipherConfig{KeyID: v.KeyID, Algorithm: v.Algorithm})
+		if err != nil {
+			return "", err
+		}
+		return c.EncryptWithAAD(v.Plaintext, v.aad())
+	case "kek":
+		kek, err := NewLocalKEK(v.KeyID, key)
+		if err != nil {
+			return "", err
+		}
+		return EncryptWithKEK(context.Background(), v.Plaintext, kek, v.aad())
+	case "deterministic":
+		d, err := NewDeterministicCipherWithKeyID(key, v.KeyID)
+		if err != nil {
+			return "", err
+		}
+		return d.EncryptWithAAD(v.Plaintext, v.aad())
+	case "hybrid":
+		recipient, err := NewHybridPrivateKey(v.KeyID, key)
+		if err != nil {
+			return "", err
+		}
+		return SealWithPublicKey(v.Plaintext, recipient.PublicKey(), v.Algorithm, v.aad())
+	case "stream":
+		var buf bytes.Buffer
+		w, err := NewEncryptWriter(&buf, key, StreamConfig{ChunkSize: v.ChunkSize, KeyID: v.KeyID})
+		if err != nil {
+			return "", err
+		}
+		if _, err = w.Write([]byte(v.Plaintext)); err != nil {
+			return "", err
+		}
+		if err = w.Close(); err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	default:
+		return "", fmt.Errorf("암호화할 수 없는 벡터 종류: %s", v.Kind)
+	}
+}
+
+func decryptVector(t *testing.T, v testVector) (string, error) {
+	t.Helper()
+	key := v.key(t)
+
+	switch v.Kind {
+	case "envelope", "legacy":
+		c, err := NewCipher(key)
+		if err != nil {
+			return "", err
+		}
+		return c.DecryptWithAAD(v.Ciphertext, v.aad())
+	case "kek":
+		kek, err := NewLocalKEK(v.KeyID, key)
+		if err != nil {
+			return "", err
+		}
+		return DecryptWithKEK(context.Background(), v.Ciphertext, kek, v.aad())
+	case "deterministic":
+		d, err := NewDeterministicCipher(key)
+		if err != nil {
+			return "", err
+		}
+		return d.DecryptWithAAD(v.Ciphertext, v.aad())
+	case "hybrid":
+		recipient, err := NewHybridPrivateKey(v.KeyID, key)
+		if err != nil {
+			return "", err
+		}
+		return OpenWithPrivateKey(v.Ciphertext, recipient, v.aad())
+	case "stream":
+		raw, err := base64.StdEncoding.DecodeString(v.Ciphertext)
+		if err != nil {
+			return "", err
+		}
+		r, err := NewDecryptReader(bytes.NewReader(raw), key)
+		if err != nil {
+			return "", err
+		}
+		plainText, err := io.ReadAll(r)
+		return string(plainText), err
+	default:
+		return "", fmt.Errorf("알 수 없는 벡터 종류: %s", v.Kind)
+	}
+}
+
+func TestKnownAnswerVectors(t *testing.T) {
+	covered := map[string]bool{}
+	for _, v := range loadTestVectors(t, "vectors.json") {
+		covered[fmt.Sprintf("%s/%s", v.Kind, v.Algorithm)] = true
+
+		t.Run(v.Name, func(t *testing.T) {
+			if v.Kind != "legacy" {
+				encrypted, err := withRandom(t, v.Random, func() (string, error) { return encryptVector(t, v) })
+				require.NoError(t, err)
+				require.Equal(t, v.Ciphertext, encrypted)
+			}
+
+			decrypted, err := decryptVector(t, v)
+			require.NoError(t, err)
+			require.Equal(t, v.Plaintext, decrypted)
+		})
+	}
+
+	// 모든 알고리즘과 봉투 버전에 벡터가 있어야 한다
+	for _, want := range []string{
+		"legacy/" + AlgorithmAES256GCM.String(),
+		"envelope/" + AlgorithmAES256GCM.String(),
+		"envelope/" + AlgorithmChaCha20Poly1305.String(),
+		"envelope/" + AlgorithmXChaCha20Poly1305.String(),
+		"kek/" + AlgorithmAES256GCM.String(),
+		"deterministic/" + AlgorithmAES256SIV.String(),
+		"hybrid/" + AlgorithmAES256GCM.String(),
+		"hybrid/" + AlgorithmChaCha20Poly1305.String(),
+		"stream/" + AlgorithmAES256GCM.String(),
+	} {
+		require.True(t, covered[want], "벡터 없음: %s", want)
+	}
+}
+
+func TestCompatibilityFixtures(t *testing.T) {
+	for _, v := range loadTestVectors(t, "compat.json") {
+		t.Run(v.Name, func(t *testing.T) {
+			decrypted, err := decryptVector(t, v)
+			require.NoError(t, err)
+			require.Equal(t, v.Plaintext, decrypted)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/hsjahng/cmp-common/crypto"
+)
+
+// SignatureHeader 서명을 담는 Kafka 메시지 헤더 키
+const SignatureHeader = "cmp-signature"
+
+// CanonicalResourceModel 서명 대상이 되는 ResourceModel 의 정규화 JSON
+//
+// Data 의 원본 JSON 공백이나 키 순서가 달라도 같은 바이트가 나오도록 crypto.CanonicalJSON 으로 정규화한다.
+func CanonicalResourceModel(model *ResourceModel) ([]byte, error) {
+	if model == nil {
+		return nil, errors.New("model은 비어있을 수 없습니다")
+	}
+	payload, err := json.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("ResourceModel 직렬화 실패: %w", err)
+	}
+	return crypto.CanonicalJSON(payload)
+}
+
+// SignResourceModel ResourceModel 정규화 JSON 에 서명
+func SignResourceModel(model *ResourceModel, signer crypto.Signer) (*crypto.Signature, error) {
+	canonical, err := CanonicalResourceModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(canonical)
+}
+
+// VerifyResourceModel ResourceModel 서명 검증
+func VerifyResourceModel(model *ResourceModel, sig *crypto.Signature, verifier crypto.Verifier) error {
+	canonical, err := CanonicalResourceModel(model)
+	if err != nil {
+		return err
+	}
+	return verifier.Verify(canonical, sig)
+}
+
+// NewSignedMessage ResourceModel 을 서명 헤더가 붙은 프로듀서 메시지로 만든다
+func NewSignedMessage(topic string, model *ResourceModel, signer crypto.Signer) (*sarama.ProducerMessage, error) {
+	payload, err := json.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("ResourceModel 직렬화 실패: %w", err)
+	}
+	sig, err := SignResourceModel(model, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(payload),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(SignatureHeader), Value: []byte(sig.Encode())},
+		},
+	}, nil
+}
+
+// VerifyMessage 컨슈머 메시지의 서명 헤더를 검증하고 ResourceModel 을 반환
+//
+// 서명 헤더가 없거나 검증에 실패하면 오류를 반환하므로, 허용된 수집기가 아닌 곳에서 넣은 메시지는 처리하지 않는다.
+func VerifyMessage(message *sarama.ConsumerMessage, verifier crypto.Verifier) (*ResourceModel, error) {
+	sig, err := SignatureFromHeaders(message.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var model ResourceModel
+	if err = json.Unmarshal(message.Value, &model); err != nil {
+		return nil, fmt.Errorf("ResourceModel 파싱 실패: %w", err)
+	}
+	if err = VerifyResourceModel(&model, sig, verifier); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// SignatureFromHeaders 메시지 헤더에서 서명 읽기
+func SignatureFromHeaders(headers []*sarama.RecordHeader) (*crypto.Signature, error) {
+	for _, h := range headers {
+		if h != nil && string(h.Key) == SignatureHeader {
+			return crypto.ParseSignature(string(h.Value))
+		}
+	}
+	return nil, fmt.Errorf("%s 헤더가 없습니다", SignatureHeader)
+}
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/hsjahng/cmp-common/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResourceModel() *ResourceModel {
+	return &ResourceModel{
+		Resource: Resource{ProviderId: "p-1", ObjectType: "aws", DataType: "meta"},
+		ScopeData: ScopeData{
+			Scope: Scope{NodeType: "instance"},
+			Data:  []json.RawMessage{json.RawMessage(`{ "id": "i-1", "cpu": 2 }`)},
+		},
+	}
+}
+
+// consumed 프로듀서 메시지를 컨슈머가 받은 메시지로 변환
+func consumed(t *testing.T, message *sarama.ProducerMessage) *sarama.ConsumerMessage {
+	t.Helper()
+	value, err := message.Value.Encode()
+	require.NoError(t, err)
+
+	headers := make([]*sarama.RecordHeader, len(message.Headers))
+	for i := range message.Headers {
+		headers[i] = &message.Headers[i]
+	}
+	return &sarama.ConsumerMessage{Topic: message.Topic, Value: value, Headers: headers}
+}
+
+func TestResourceModelSignature(t *testing.T) {
+	collector, err := crypto.GenerateEd25519Signer("collector-1")
+	require.NoError(t, err)
+	verifier, err := crypto.NewEd25519Verifier("collector-1", collector.PublicKey())
+	require.NoError(t, err)
+	trusted, err := crypto.NewVerifierSet(verifier)
+	require.NoError(t, err)
+
+	t.Run("CanonicalData", func(t *testing.T) {
+		model := newTestResourceModel()
+		sig, err := SignResourceModel(model, collector)
+		require.NoError(t, err)
+
+		// 데이터의 공백이나 키 순서가 달라도 같은 서명으로 검증
+		model.ScopeData.Data[0] = json.RawMessage(`{"cpu":2,"id":"i-1"}`)
+		require.NoError(t, VerifyResourceModel(model, sig, trusted))
+
+		model.ScopeData.Data[0] = json.RawMessage(`{"cpu":4,"id":"i-1"}`)
+		require.ErrorIs(t, VerifyResourceModel(model, sig, trusted), crypto.ErrAuthenticationFailed)
+	})
+
+	t.Run("SignedMessage", func(t *testing.T) {
+		message, err := NewSignedMessage(Topic, newTestResourceModel(), collector)
+		require.NoError(t, err)
+		require.Equal(t, Topic, message.Topic)
+
+		model, err := VerifyMessage(consumed(t, message), trusted)
+		require.NoError(t, err)
+		require.Equal(t, "p-1", model.Resource.ProviderId)
+	})
+
+	t.Run("InjectedMessage", func(t *testing.T) {
+		// 서명 없이 넣은 메시지
+		payload, err := json.Marshal(newTestResourceModel())
+		require.NoError(t, err)
+		_, err = VerifyMessage(&sarama.ConsumerMessage{Topic: Topic, Value: payload}, trusted)
+		require.Error(t, err)
+
+		// 허용되지 않은 수집기가 서명한 메시지
+		intruder, err := crypto.GenerateEd25519Signer("intruder")
+		require.NoError(t, err)
+		message, err := NewSignedMessage(Topic, newTestResourceModel(), intruder)
+		require.NoError(t, err)
+		_, err = VerifyMessage(consumed(t, message), trusted)
+		require.ErrorIs(t, err, crypto.ErrUnknownKeyID)
+
+		// 서명 후 값이 바뀐 메시지
+		message, err = NewSignedMessage(Topic, newTestResourceModel(), collector)
+		require.NoError(t, err)
+		tampered := newTestResourceModel()
+		tampered.Resource.ProviderId = "p-2"
+		payload, err = json.Marshal(tampered)
+		require.NoError(t, err)
+		message.Value = sarama.ByteEncoder(payload)
+		_, err = VerifyMessage(consumed(t, message), trusted)
+		require.ErrorIs(t, err, crypto.ErrAuthenticationFailed)
+	})
+
+	t.Run("HMAC", func(t *testing.T) {
+		signer, err := crypto.NewHMACSigner("collector-hmac", crypto.CreateKeyFromString("shared-key"))
+		require.NoError(t, err)
+
+		message, err := NewSignedMessage(Topic, newTestResourceModel(), signer)
+		require.NoError(t, err)
+		_, err = VerifyMessage(consumed(t, message), signer)
+		require.NoError(t, err)
+	})
+}
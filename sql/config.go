@@ -0,0 +1,463 @@
+package sql
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hsjahng/cmp-common/crypto"
+	"gopkg.in/yaml.v3"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// DB 접속 설정
+//
+// DSN 을 코드에 직접 쓰지 않고 DBConfig 로 만든다. 설정은 아래 순서로 덮어쓴다 (뒤가 우선).
+//
+//	DefaultDBConfig() < YAML 파일 < 환경 변수 < 명령행 플래그
+//
+// type 은 MARIADB(기본값), POSTGRES, SQLITE 또는 RegisterDriver 로 등록한 종류이고, port 와 params 는
+// 비워두면 종류별 기본값(MariaDB 는 3306, charset=utf8mb4&parseTime=True&loc=Local)을 쓴다.
+//
+// 비밀번호는 password 에 평문이나 crypto 로 암호화한 값("cmp:" 로 시작, cmpcrypt encrypt 로 생성)을 넣거나
+// passwordFile 로 Kubernetes secret 등 파일에서 읽는다. 암호화한 비밀번호의 키는 PasswordKeys 또는
+// PasswordKeyEnv 환경 변수(base64 32바이트)로 지정한다. 명령행 플래그로는 비밀번호를 받지 않는다.
+// 우선순위가 높은 출처에서 둘 중 하나를 지정하면 낮은 출처의 password, passwordFile 은 모두 무시한다.
+//
+//	type: MARIADB
+//	host: 172.10.50.30
+//	port: 32006
+//	user: maestro
+//	password: cmp:AQdkYi0yMDI0...
+//	database: dp_common_pds_test
+//	params:
+//	  charset: utf8mb4
+//	tls: skip-verify
+//
+// 로그에는 항상 RedactedDSN 을 남긴다. DBConfig 를 fmt 로 출력해도 비밀번호는 가려진다.
+
+const (
+	// DefaultDBPort MariaDB 기본 포트
+	DefaultDBPort = 3306
+	// DefaultPasswordKeyEnv 암호화한 비밀번호의 키를 읽는 기본 환경 변수 (cmpcrypt 와 같음)
+	DefaultPasswordKeyEnv = "CMPCRYPT_KEY"
+	// RedactedPassword 로그에 남기는 DSN 의 비밀번호 자리
+	RedactedPassword = "*****"
+)
+
+// DBConfig DB 접속 설정
+type DBConfig struct {
+	Type         DbType            `yaml:"type"` // 비어있으면 MYSQL
+	Host         string            `yaml:"host"`
+	Port         int               `yaml:"port"` // 0 이면 종류별 기본 포트
+	User         string            `yaml:"user"`
+	Password     string            `yaml:"password"`     // 평문 또는 crypto 로 암호화한 값
+	PasswordFile string            `yaml:"passwordFile"` // 비밀번호 파일, 같은 출처에서 함께 지정하면 Password 보다 우선
+	Database     string            `yaml:"database"`
+	Params       map[string]string `yaml:"params"` // DSN 쿼리 파라미터, 종류별 기본 파라미터에 덮어쓴다
+	TLS          string            `yaml:"tls"`    // MariaDB: true, skip-verify, preferred 또는 등록한 TLS 설정 이름, PostgreSQL: sslmode
+
+	LogLevel gormLogger.LogLevel `yaml:"logLevel"` // ConnectWithRetry 의 gorm 로그 레벨 (1 Silent, 2 Error, 3 Warn, 4 Info), 0 이면 Warn
+	Pool     PoolConfig          `yaml:"pool"`     // 커넥션 풀 설정
+
+	PasswordKeyEnv string           `yaml:"passwordKeyEnv"` // 암호화한 비밀번호의 키를 읽을 환경 변수
+	PasswordKeys   crypto.KeyLookup `yaml:"-"`              // 암호화한 비밀번호의 키 조회, 지정하면 PasswordKeyEnv 보다 우선
+}
+
+// DefaultDBConfig 기본 설정 (MariaDB, 기존 DB_COMMON 과 같은 파라미터)
+func DefaultDBConfig() DBConfig {
+	return DBConfig{
+		Type:           MYSQL,
+		PasswordKeyEnv: DefaultPasswordKeyEnv,
+		Pool:           DefaultPoolConfig(),
+	}
+}
+
+// Validate 필수 항목 확인
+func (c DBConfig) Validate() error {
+	driver, err := c.driver()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if driver.ServerRequired {
+		if len(c.Host) == 0 {
+			errs = append(errs, errors.New("host는 비어있을 수 없습니다"))
+		}
+		if c.Port < 0 || c.Port > 65535 {
+			errs = append(errs, fmt.Errorf("port가 올바르지 않습니다: %d", c.Port))
+		}
+		if len(c.User) == 0 {
+			errs = append(errs, errors.New("user는 비어있을 수 없습니다"))
+		}
+	}
+	if len(c.Database) == 0 {
+		errs = append(errs, errors.New("database는 비어있을 수 없습니다"))
+	}
+	return errors.Join(errs...)
+}
+
+// driver Type 의 드라이버
+func (c DBConfig) driver() (Driver, error) {
+	if len(c.Type) == 0 {
+		return LookupDriver(MYSQL)
+	}
+	return LookupDriver(c.Type)
+}
+
+// DSN 비밀번호를 읽어(필요하면 복호화) 접속용 DSN 생성, 로그에는 RedactedDSN 을 사용
+func (c DBConfig) DSN() (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	password, err := c.ResolvePassword()
+	if err != nil {
+		return "", err
+	}
+	return c.formatDSN(password), nil
+}
+
+// RedactedDSN 비밀번호를 가린 DSN
+func (c DBConfig) RedactedDSN() string {
+	password := ""
+	if len(c.Password) > 0 || len(c.PasswordFile) > 0 {
+		password = RedactedPassword
+	}
+	return c.formatDSN(password)
+}
+
+// String 비밀번호를 가린 DSN (fmt 로 출력해도 비밀번호가 남지 않도록)
+func (c DBConfig) String() string {
+	return c.RedactedDSN()
+}
+
+// GoString %#v 출력도 비밀번호를 가린다
+func (c DBConfig) GoString() string {
+	return "sql.DBConfig(" + c.RedactedDSN() + ")"
+}
+
+// formatDSN 종류별 기본 포트, 파라미터를 채워 드라이버 형식으로 DSN 생성
+func (c DBConfig) formatDSN(password string) string {
+	driver, err := c.driver()
+	if err != nil {
+		return fmt.Sprintf("%s(%s)", c.Type, c.Database)
+	}
+	if c.Port == 0 {
+		c.Port = driver.DefaultPort
+	}
+
+	params := make(map[string]string, len(driver.DefaultParams)+len(c.Params)+1)
+	for k, v := range driver.DefaultParams {
+		params[k] = v
+	}
+	for k, v := range c.Params {
+		params[k] = v
+	}
+	c.Params = params
+	return driver.FormatDSN(c, password)
+}
+
+// logLevel gorm 로그 레벨, 지정하지 않았으면 Warn
+func (c DBConfig) logLevel() gormLogger.LogLevel {
+	if c.LogLevel == 0 {
+		return gormLogger.Warn
+	}
+	return c.LogLevel
+}
+
+// ResolvePassword PasswordFile 또는 Password 에서 비밀번호를 읽고, 암호화되어 있으면 복호화
+func (c DBConfig) ResolvePassword() (string, error) {
+	password := c.Password
+	if len(c.PasswordFile) > 0 {
+		data, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("비밀번호 파일 읽기 실패: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+	if !crypto.IsEnvelope(password) {
+		return password, nil
+	}
+
+	lookup := c.PasswordKeys
+	if lookup == nil {
+		lookup = c.envKeyLookup
+	}
+	decrypted, err := crypto.DecryptWithKeyLookup(password, lookup)
+	if err != nil {
+		return "", fmt.Errorf("비밀번호 복호화 실패: %w", err)
+	}
+	return decrypted, nil
+}
+
+// envKeyLookup PasswordKeyEnv 환경 변수의 base64 키
+func (c DBConfig) envKeyLookup(string) ([]byte, error) {
+	name := c.PasswordKeyEnv
+	if len(name) == 0 {
+		name = DefaultPasswordKeyEnv
+	}
+	encoded := strings.TrimSpace(os.Getenv(name))
+	if len(encoded) == 0 {
+		return nil, fmt.Errorf("암호화된 비밀번호의 키가 없습니다: %s 환경 변수를 지정하세요", name)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s 환경 변수는 base64 로 인코딩된 키여야 합니다: %w", name, err)
+	}
+	return key, nil
+}
+
+// DBConfigSources LoadDBConfig 가 읽을 설정 위치
+type DBConfigSources struct {
+	File      string   // YAML 파일 (비어있으면 읽지 않음)
+	EnvPrefix string   // 환경 변수 접두사 (비어있으면 읽지 않음), 예: "CMP_DB" 이면 CMP_DB_HOST
+	Flags     *DBFlags // RegisterDBFlags 로 등록하고 Parse 한 플래그 (nil 이면 읽지 않음)
+}
+
+// LoadDBConfig 기본값, YAML 파일, 환경 변수, 플래그 순서로 덮어써 DBConfig 생성
+func LoadDBConfig(sources DBConfigSources) (DBConfig, error) {
+	config := DefaultDBConfig()
+
+	file := sources.File
+	if sources.Flags != nil && sources.Flags.visited()["config"] {
+		file = sources.Flags.file
+	}
+	if len(file) > 0 {
+		if err := config.loadYAML(file); err != nil {
+			return DBConfig{}, err
+		}
+	}
+	if len(sources.EnvPrefix) > 0 {
+		if err := config.applyEnv(sources.EnvPrefix); err != nil {
+			return DBConfig{}, err
+		}
+	}
+	if sources.Flags != nil {
+		if err := sources.Flags.apply(&config); err != nil {
+			return DBConfig{}, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return DBConfig{}, err
+	}
+	return config, nil
+}
+
+// loadYAML YAML 파일의 값으로 덮어쓰기, 파일에 없는 항목은 그대로 둔다
+func (c *DBConfig) loadYAML(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("DB 설정 파일 읽기 실패: %w", err)
+	}
+
+	var file DBConfig
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("DB 설정 파일 파싱 실패: %w", err)
+	}
+	c.merge(file)
+	return nil
+}
+
+// merge 비어있지 않은 값으로 덮어쓰기, Params 는 키 단위로 합친다
+func (c *DBConfig) merge(o DBConfig) {
+	if len(o.Type) > 0 {
+		c.Type = o.Type
+	}
+	if len(o.Host) > 0 {
+		c.Host = o.Host
+	}
+	if o.Port != 0 {
+		c.Port = o.Port
+	}
+	if len(o.User) > 0 {
+		c.User = o.User
+	}
+	// 비밀번호는 둘 중 하나만 지정해도 낮은 출처의 값을 모두 대체
+	if len(o.Password) > 0 || len(o.PasswordFile) > 0 {
+		c.Password, c.PasswordFile = o.Password, o.PasswordFile
+	}
+	if len(o.Database) > 0 {
+		c.Database = o.Database
+	}
+	if len(o.Params) > 0 {
+		params := make(map[string]string, len(c.Params)+len(o.Params))
+		for k, v := range c.Params {
+			params[k] = v
+		}
+		for k, v := range o.Params {
+			params[k] = v
+		}
+		c.Params = params
+	}
+	if len(o.TLS) > 0 {
+		c.TLS = o.TLS
+	}
+	if len(o.PasswordKeyEnv) > 0 {
+		c.PasswordKeyEnv = o.PasswordKeyEnv
+	}
+	if o.LogLevel != 0 {
+		c.LogLevel = o.LogLevel
+	}
+	c.Pool.merge(o.Pool)
+}
+
+// applyEnv 환경 변수로 덮어쓰기
+//
+//	<prefix>_TYPE, _HOST, _PORT, _USER, _PASSWORD, _PASSWORD_FILE, _DATABASE, _PARAMS(k=v&k2=v2), _TLS, _PASSWORD_KEY_ENV,
+//	_MAX_OPEN_CONNS, _MAX_IDLE_CONNS, _CONN_MAX_LIFETIME(1h), _CONN_MAX_IDLE_TIME(30m)
+func (c *DBConfig) applyEnv(prefix string) error {
+	env := func(name string) string {
+		return os.Getenv(prefix + "_" + name)
+	}
+
+	o := DBConfig{
+		Type:           DbType(env("TYPE")),
+		Host:           env("HOST"),
+		User:           env("USER"),
+		Password:       env("PASSWORD"),
+		PasswordFile:   env("PASSWORD_FILE"),
+		Database:       env("DATABASE"),
+		TLS:            env("TLS"),
+		PasswordKeyEnv: env("PASSWORD_KEY_ENV"),
+	}
+	if port := env("PORT"); len(port) > 0 {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return fmt.Errorf("%s_PORT 가 숫자가 아닙니다: %w", prefix, err)
+		}
+		o.Port = p
+	}
+	if params := env("PARAMS"); len(params) > 0 {
+		p, err := parseParams(params)
+		if err != nil {
+			return fmt.Errorf("%s_PARAMS 형식 오류: %w", prefix, err)
+		}
+		o.Params = p
+	}
+	for name, value := range map[string]*int{"MAX_OPEN_CONNS": &o.Pool.MaxOpenConns, "MAX_IDLE_CONNS": &o.Pool.MaxIdleConns} {
+		if v := env(name); len(v) > 0 {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s_%s 가 숫자가 아닙니다: %w", prefix, name, err)
+			}
+			*value = n
+		}
+	}
+	for name, value := range map[string]*time.Duration{"CONN_MAX_LIFETIME": &o.Pool.ConnMaxLifetime, "CONN_MAX_IDLE_TIME": &o.Pool.ConnMaxIdleTime} {
+		if v := env(name); len(v) > 0 {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s_%s 형식 오류: %w", prefix, name, err)
+			}
+			*value = d
+		}
+	}
+	c.merge(o)
+	return nil
+}
+
+// parseParams "k=v&k2=v2" 형식 파라미터
+func parseParams(s string) (map[string]string, error) {
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]string, len(values))
+	for k, v := range values {
+		params[k] = v[len(v)-1]
+	}
+	return params, nil
+}
+
+// DBFlags 명령행 플래그로 받은 DB 설정
+type DBFlags struct {
+	fs    *flag.FlagSet
+	names map[string]string // 플래그 이름 -> 접두사를 뺀 이름
+
+	file         string
+	dbType       string
+	host         string
+	port         int
+	user         string
+	passwordFile string
+	database     string
+	params       string
+	tls          string
+}
+
+// RegisterDBFlags DB 설정 플래그 등록 (-<prefix>config, -<prefix>host 등), fs 를 Parse 한 뒤 LoadDBConfig 에 넘긴다
+//
+// 비밀번호는 프로세스 목록에 남지 않도록 플래그로 받지 않고 -<prefix>password-file 로만 받는다.
+func RegisterDBFlags(fs *flag.FlagSet, prefix string) *DBFlags {
+	f := &DBFlags{fs: fs, names: map[string]string{}}
+	fs.StringVar(&f.file, prefix+"config", "", "DB 설정 YAML 파일")
+	fs.StringVar(&f.dbType, prefix+"type", "", "DB 종류 (MARIADB, POSTGRES, SQLITE)")
+	fs.StringVar(&f.host, prefix+"host", "", "DB 호스트")
+	fs.IntVar(&f.port, prefix+"port", 0, "DB 포트 (0 이면 종류별 기본 포트)")
+	fs.StringVar(&f.user, prefix+"user", "", "DB 사용자")
+	fs.StringVar(&f.passwordFile, prefix+"password-file", "", "DB 비밀번호 파일 (평문 또는 암호화한 값)")
+	fs.StringVar(&f.database, prefix+"database", "", "DB 이름 (SQLite 는 파일 경로)")
+	fs.StringVar(&f.params, prefix+"params", "", "DSN 파라미터 (k=v&k2=v2)")
+	fs.StringVar(&f.tls, prefix+"tls", "", "TLS 설정 (true, skip-verify, preferred 등)")
+
+	for _, name := range []string{"config", "type", "host", "port", "user", "password-file", "database", "params", "tls"} {
+		f.names[prefix+name] = name
+	}
+	return f
+}
+
+// visited 명시적으로 지정한 플래그 (접두사를 뺀 이름)
+func (f *DBFlags) visited() map[string]bool {
+	set := map[string]bool{}
+	f.fs.Visit(func(fl *flag.Flag) {
+		if name, ok := f.names[fl.Name]; ok {
+			set[name] = true
+		}
+	})
+	return set
+}
+
+// apply 명시적으로 지정한 플래그만 덮어쓰기
+func (f *DBFlags) apply(c *DBConfig) error {
+	set := f.visited()
+
+	var o DBConfig
+	if set["type"] {
+		o.Type = DbType(f.dbType)
+	}
+	if set["host"] {
+		o.Host = f.host
+	}
+	if set["port"] {
+		o.Port = f.port
+	}
+	if set["user"] {
+		o.User = f.user
+	}
+	if set["password-file"] {
+		o.PasswordFile = f.passwordFile
+	}
+	if set["database"] {
+		o.Database = f.database
+	}
+	if set["tls"] {
+		o.TLS = f.tls
+	}
+	if set["params"] {
+		params, err := parseParams(f.params)
+		if err != nil {
+			return fmt.Errorf("params 플래그 형식 오류: %w", err)
+		}
+		o.Params = params
+	}
+	c.merge(o)
+	return nil
+}
@@ -0,0 +1,235 @@
+package sql
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/hsjahng/cmp-common/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func testDBConfig() DBConfig {
+	config := DefaultDBConfig()
+	config.Host = "172.10.50.30"
+	config.Port = 32006
+	config.User = "maestro"
+	config.Password = "s3cr&t"
+	config.Database = "dp_common_pds_test"
+	return config
+}
+
+func TestDBConfigDSN(t *testing.T) {
+	config := testDBConfig()
+	dsn, err := config.DSN()
+	require.NoError(t, err)
+	require.Equal(t, "maestro:s3cr&t@tcp(172.10.50.30:32006)/dp_common_pds_test?loc=Local&parseTime=true&charset=utf8mb4", dsn)
+
+	config.TLS = "skip-verify"
+	config.Params = map[string]string{"loc": "Asia/Seoul"} // 기본 파라미터에 덮어쓴다
+	config.Host = "::1"
+	dsn, err = config.DSN()
+	require.NoError(t, err)
+	require.Equal(t, "maestro:s3cr&t@tcp([::1]:32006)/dp_common_pds_test?loc=Asia%2FSeoul&parseTime=true&tls=skip-verify&charset=utf8mb4", dsn)
+
+	config.Password = ""
+	dsn, err = config.DSN()
+	require.NoError(t, err)
+	require.Equal(t, "maestro@tcp([::1]:32006)/dp_common_pds_test?loc=Asia%2FSeoul&parseTime=true&tls=skip-verify&charset=utf8mb4", dsn)
+
+	// 드라이버가 다시 파싱했을 때 비밀번호, 파라미터가 그대로 나와야 한다
+	config = testDBConfig()
+	config.Password = "p@ss/w:rd?#"
+	config.Database = "db/name"
+	config.Params = map[string]string{"time_zone": "'+09:00'"}
+	dsn, err = config.DSN()
+	require.NoError(t, err)
+	parsed, err := mysqlDriver.ParseDSN(dsn)
+	require.NoError(t, err)
+	require.Equal(t, "maestro", parsed.User)
+	require.Equal(t, "p@ss/w:rd?#", parsed.Passwd)
+	require.Equal(t, "172.10.50.30:32006", parsed.Addr)
+	require.Equal(t, "db/name", parsed.DBName)
+	require.True(t, parsed.ParseTime)
+	require.Equal(t, "'+09:00'", parsed.Params["time_zone"])
+}
+
+func TestDBConfigRedacted(t *testing.T) {
+	config := testDBConfig()
+	require.Equal(t, "maestro:*****@tcp(172.10.50.30:32006)/dp_common_pds_test?loc=Local&parseTime=true&charset=utf8mb4", config.RedactedDSN())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		printed := fmt.Sprintf(format, config)
+		require.NotContains(t, printed, "s3cr&t", format)
+		require.Contains(t, printed, RedactedPassword, format)
+	}
+
+	require.Equal(t, "maestro:*****@tcp(172.10.50.30:32006)/db", DbDsn("maestro:p@ss:w@rd@tcp(172.10.50.30:32006)/db").Redacted())
+	require.Equal(t, "maestro@tcp(172.10.50.30:32006)/db", DbDsn("maestro@tcp(172.10.50.30:32006)/db").Redacted())
+	require.NotContains(t, DB_COMMON.Redacted(), "okestro2018")
+}
+
+func TestDBConfigEncryptedPassword(t *testing.T) {
+	key := crypto.CreateKeyFromString("db-password-key")
+	encrypted, err := crypto.EncryptWithKeyID("s3cr&t", key, "db-1")
+	require.NoError(t, err)
+
+	t.Run("KeyLookup", func(t *testing.T) {
+		config := testDBConfig()
+		config.Password = encrypted
+		config.PasswordKeys = func(keyID string) ([]byte, error) {
+			require.Equal(t, "db-1", keyID)
+			return key, nil
+		}
+		dsn, err := config.DSN()
+		require.NoError(t, err)
+		require.Contains(t, dsn, "maestro:s3cr&t@")
+		require.NotContains(t, config.RedactedDSN(), encrypted)
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD_KEY", base64.StdEncoding.EncodeToString(key))
+		config := testDBConfig()
+		config.Password = encrypted
+		config.PasswordKeyEnv = "TEST_DB_PASSWORD_KEY"
+		password, err := config.ResolvePassword()
+		require.NoError(t, err)
+		require.Equal(t, "s3cr&t", password)
+
+		config.PasswordKeyEnv = "TEST_DB_PASSWORD_KEY_MISSING"
+		_, err = config.ResolvePassword()
+		require.ErrorContains(t, err, "TEST_DB_PASSWORD_KEY_MISSING")
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		config := testDBConfig()
+		config.Password = encrypted
+		config.PasswordKeys = func(string) ([]byte, error) {
+			return crypto.CreateKeyFromString("other"), nil
+		}
+		_, err := config.DSN()
+		require.ErrorIs(t, err, crypto.ErrAuthenticationFailed)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(path, []byte(encrypted+"\n"), 0600))
+
+		// 파일이 Password 보다 우선
+		config := testDBConfig()
+		config.Password = "ignored"
+		config.PasswordFile = path
+		config.PasswordKeys = func(string) ([]byte, error) { return key, nil }
+		password, err := config.ResolvePassword()
+		require.NoError(t, err)
+		require.Equal(t, "s3cr&t", password)
+
+		config.PasswordFile = filepath.Join(t.TempDir(), "missing")
+		_, err = config.DSN()
+		require.Error(t, err)
+	})
+}
+
+func TestLoadDBConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+host: yaml-host
+port: 3307
+user: yaml-user
+password: yaml-password
+database: yaml-db
+params:
+  charset: utf8
+  timeout: 5s
+tls: preferred
+`), 0600))
+
+	t.Run("YAML", func(t *testing.T) {
+		config, err := LoadDBConfig(DBConfigSources{File: path})
+		require.NoError(t, err)
+		require.Equal(t, "yaml-host", config.Host)
+		require.Equal(t, 3307, config.Port)
+		require.Equal(t, "yaml-password", config.Password)
+		require.Equal(t, map[string]string{"charset": "utf8", "timeout": "5s"}, config.Params)
+		// 없는 파라미터는 드라이버 기본값
+		require.Equal(t, "yaml-user:*****@tcp(yaml-host:3307)/yaml-db?loc=Local&parseTime=true&timeout=5s&tls=preferred&charset=utf8", config.RedactedDSN())
+		require.Equal(t, DefaultPasswordKeyEnv, config.PasswordKeyEnv)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		t.Setenv("TEST_DB_HOST", "env-host")
+		t.Setenv("TEST_DB_PORT", "3308")
+		t.Setenv("TEST_DB_PASSWORD", "env-password")
+		t.Setenv("TEST_DB_PARAMS", "timeout=10s")
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags := RegisterDBFlags(fs, "db-")
+		require.NoError(t, fs.Parse([]string{"-db-config", path, "-db-host", "flag-host", "-db-params", "charset=utf8mb4"}))
+
+		config, err := LoadDBConfig(DBConfigSources{EnvPrefix: "TEST_DB", Flags: flags})
+		require.NoError(t, err)
+		require.Equal(t, "flag-host", config.Host)        // 플래그 > 환경 변수
+		require.Equal(t, 3308, config.Port)               // 환경 변수 > YAML, 지정하지 않은 플래그의 기본값은 무시
+		require.Equal(t, "yaml-user", config.User)        // YAML > 기본값
+		require.Equal(t, "env-password", config.Password) // 환경 변수 > YAML
+		require.Equal(t, "yaml-db", config.Database)
+		require.Equal(t, "utf8mb4", config.Params["charset"])
+		require.Equal(t, "10s", config.Params["timeout"])
+		require.Equal(t, "preferred", config.TLS)
+	})
+
+	t.Run("PasswordPrecedence", func(t *testing.T) {
+		dir := t.TempDir()
+		passwordFile := filepath.Join(dir, "password")
+		require.NoError(t, os.WriteFile(passwordFile, []byte("file-password\n"), 0600))
+		filePath := filepath.Join(dir, "db.yaml")
+		require.NoError(t, os.WriteFile(filePath, []byte("host: yaml-host\nuser: yaml-user\ndatabase: yaml-db\npasswordFile: "+passwordFile+"\n"), 0600))
+
+		config, err := LoadDBConfig(DBConfigSources{File: filePath, EnvPrefix: "TEST_DB"})
+		require.NoError(t, err)
+		password, err := config.ResolvePassword()
+		require.NoError(t, err)
+		require.Equal(t, "file-password", password)
+
+		// 환경 변수의 password 가 YAML 의 passwordFile 보다 우선
+		t.Setenv("TEST_DB_PASSWORD", "env-password")
+		config, err = LoadDBConfig(DBConfigSources{File: filePath, EnvPrefix: "TEST_DB"})
+		require.NoError(t, err)
+		require.Empty(t, config.PasswordFile)
+		password, err = config.ResolvePassword()
+		require.NoError(t, err)
+		require.Equal(t, "env-password", password)
+
+		// 반대로 환경 변수의 passwordFile 은 YAML 의 password 보다 우선
+		t.Setenv("TEST_DB_PASSWORD", "")
+		t.Setenv("TEST_DB_PASSWORD_FILE", passwordFile)
+		config, err = LoadDBConfig(DBConfigSources{File: path, EnvPrefix: "TEST_DB"})
+		require.NoError(t, err)
+		require.Empty(t, config.Password)
+		password, err = config.ResolvePassword()
+		require.NoError(t, err)
+		require.Equal(t, "file-password", password)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := LoadDBConfig(DBConfigSources{})
+		require.ErrorContains(t, err, "host")
+		require.ErrorContains(t, err, "database")
+
+		_, err = LoadDBConfig(DBConfigSources{File: filepath.Join(t.TempDir(), "missing.yaml")})
+		require.Error(t, err)
+
+		t.Setenv("TEST_DB_PORT", "port")
+		_, err = LoadDBConfig(DBConfigSources{File: path, EnvPrefix: "TEST_DB"})
+		require.ErrorContains(t, err, "TEST_DB_PORT")
+
+		config := testDBConfig()
+		config.Port = 70000
+		_, err = config.DSN()
+		require.ErrorContains(t, err, "port")
+	})
+}
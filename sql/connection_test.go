@@ -1,13 +1,20 @@
 package sql
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/hsjahng/cmp-common/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zapcore"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -105,3 +112,83 @@ func Test_GetDB(t *testing.T) {
 		mariaDB.Close()
 	})
 }
+
+// mockDialector attempt 번째 접속에서 pingErrs[attempt] 를 반환하는 sqlmock 연결
+func mockDialector(t *testing.T, pingErrs ...error) *int {
+	t.Helper()
+	original := newDialector
+	t.Cleanup(func() { newDialector = original })
+
+	attempts := 0
+	newDialector = func(Driver, string) gorm.Dialector {
+		conn, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		ping := mock.ExpectPing()
+		if attempts < len(pingErrs) {
+			ping.WillReturnError(pingErrs[attempts])
+		}
+		mock.ExpectClose()
+		attempts++
+		return mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true})
+	}
+	return &attempts
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	ctx := context.Background()
+	config := testDBConfig()
+
+	t.Run("Success", func(t *testing.T) {
+		attempts := mockDialector(t, driver.ErrBadConn, driver.ErrBadConn)
+		retryConfig, logs := testRetryConfig(3)
+
+		db, err := ConnectWithRetry(ctx, config, retryConfig)
+		require.NoError(t, err)
+		require.NotNil(t, db)
+		require.Equal(t, 3, *attempts)
+
+		// 로그에는 비밀번호를 가린 DSN 만 남는다
+		require.Equal(t, 2, logs.FilterMessageSnippet(config.RedactedDSN()).FilterLevelExact(zapcore.WarnLevel).Len())
+		for _, entry := range logs.All() {
+			require.NotContains(t, entry.Message, config.Password)
+		}
+	})
+
+	t.Run("NonRetryable", func(t *testing.T) {
+		attempts := mockDialector(t, &mysqlDriver.MySQLError{Number: 1049, Message: "Unknown database"})
+		retryConfig, _ := testRetryConfig(3)
+
+		_, err := ConnectWithRetry(ctx, config, retryConfig)
+		require.True(t, IsNonRetryableError(err))
+		require.Equal(t, 1, *attempts)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		unreachable := config
+		unreachable.Host = "127.0.0.1"
+		unreachable.Port = 1
+		retryConfig, logs := testRetryConfig(2)
+
+		_, err := ConnectWithRetry(ctx, unreachable, retryConfig)
+		require.ErrorContains(t, err, "3회 시도")
+		require.Equal(t, 2, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		_, err := ConnectWithRetry(ctx, DBConfig{}, DefaultRetryConfig)
+		require.ErrorContains(t, err, "host")
+	})
+}
+
+func TestRetryConnection(t *testing.T) {
+	conn, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), newGormConfig(gormLogger.Silent, true))
+	require.NoError(t, err)
+
+	mock.ExpectPing().WillReturnError(fmt.Errorf("read tcp: %w", syscall.ECONNRESET))
+	mock.ExpectPing()
+	retryConfig, _ := testRetryConfig(2)
+	require.NoError(t, RetryConnection(db, &retryConfig))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
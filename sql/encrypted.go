@@ -0,0 +1,227 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/hsjahng/cmp-common/crypto"
+	"gorm.io/gorm/schema"
+)
+
+// 컬럼 암호화 serializer
+//
+//	type Account struct {
+//		ID        string
+//		SecretKey string  `gorm:"serializer:encrypted"`
+//		Password  *string `gorm:"serializer:encrypted"` // NULL 허용
+//	}
+//
+// RegisterEncryptedSerializer 로 한 번 등록해 두면 GetDB 로 얻은 *gorm.DB 에서
+// 저장할 때 암호화하고 읽을 때 복호화한다. 지원하는 필드 타입은 string, *string, []byte 이다.
+// nil 은 NULL 로, 빈 값은 빈 값 그대로 저장한다.
+//
+// BindPrimaryKey 를 켜면 테이블 이름, 컬럼 이름, 기본 키 값을 aad 로 묶어 다른 행이나 컬럼으로 옮긴 암호문은 복호화되지 않는다.
+// 이 경우 저장 전에 기본 키가 정해져 있어야 하므로 자동 증가 키 테이블에서는 사용할 수 없고,
+// 조회할 때 기본 키 컬럼이 암호화 컬럼보다 먼저 select 되어야 한다.
+// 기본 키에 묶이지 않은 기존 형식 값은 다른 행에서 복사해 온 값과 구분할 수 없으므로 읽지 않으며,
+// crypto.Keyring.ReencryptWithAAD 로 옮기는 동안에만 AllowLegacy 로 허용한다.
+
+// EncryptedSerializerName gorm 태그에 사용하는 serializer 이름
+const EncryptedSerializerName = "encrypted"
+
+// KeyProvider 암호화 serializer 가 사용할 키링 제공자
+type KeyProvider interface {
+	Keyring(ctx context.Context) (*crypto.Keyring, error)
+}
+
+// KeyProviderFunc 함수를 KeyProvider 로 사용
+type KeyProviderFunc func(ctx context.Context) (*crypto.Keyring, error)
+
+// Keyring KeyProvider 구현
+func (f KeyProviderFunc) Keyring(ctx context.Context) (*crypto.Keyring, error) {
+	return f(ctx)
+}
+
+// StaticKeyProvider 고정된 키링을 제공하는 KeyProvider
+func StaticKeyProvider(keyring *crypto.Keyring) KeyProvider {
+	return KeyProviderFunc(func(context.Context) (*crypto.Keyring, error) {
+		return keyring, nil
+	})
+}
+
+// EncryptedSerializerConfig 컬럼 암호화 serializer 설정
+type EncryptedSerializerConfig struct {
+	KeyProvider    KeyProvider // 키링 제공자 (필수)
+	BindPrimaryKey bool        // 테이블, 컬럼, 기본 키 값을 aad 로 묶을지 여부
+	AllowLegacy    bool        // BindPrimaryKey 를 켠 상태에서 aad 에 묶이지 않은 기존 형식 값도 읽을지 여부
+}
+
+// DefaultEncryptedSerializerConfig 기본 설정 (기본 키 바인딩 사용, KeyProvider 는 직접 지정)
+func DefaultEncryptedSerializerConfig() EncryptedSerializerConfig {
+	return EncryptedSerializerConfig{
+		BindPrimaryKey: true,
+	}
+}
+
+// EncryptedSerializer crypto 패키지로 컬럼 값을 암복호화하는 gorm serializer
+type EncryptedSerializer struct {
+	config EncryptedSerializerConfig
+}
+
+// NewEncryptedSerializer EncryptedSerializer 생성
+func NewEncryptedSerializer(config EncryptedSerializerConfig) (*EncryptedSerializer, error) {
+	if config.KeyProvider == nil {
+		return nil, errors.New("KeyProvider 는 비어있을 수 없습니다")
+	}
+	return &EncryptedSerializer{config: config}, nil
+}
+
+// RegisterEncryptedSerializer "encrypted" serializer 를 gorm 에 등록
+func RegisterEncryptedSerializer(config EncryptedSerializerConfig) error {
+	s, err := NewEncryptedSerializer(config)
+	if err != nil {
+		return err
+	}
+	schema.RegisterSerializer(EncryptedSerializerName, s)
+	return nil
+}
+
+// Scan DB 값을 복호화해 필드에 설정
+func (s *EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType).Elem()
+
+	if dbValue != nil {
+		var encrypted string
+		switch v := dbValue.(type) {
+		case []byte:
+			encrypted = string(v)
+		case string:
+			encrypted = v
+		default:
+			return fmt.Errorf("%s: 암호화 컬럼 값은 문자열이어야 합니다: %T", field.Name, dbValue)
+		}
+
+		var plainText string
+		if len(encrypted) > 0 {
+			keyring, aad, err := s.prepare(ctx, field, dst)
+			if err != nil {
+				return err
+			}
+			if aad != nil && !crypto.IsEnvelope(encrypted) {
+				if !s.config.AllowLegacy {
+					return fmt.Errorf("%s: 기본 키에 묶이지 않은 기존 형식 값은 읽을 수 없습니다: %w", field.Name, crypto.ErrMalformedCiphertext)
+				}
+				aad = nil
+			}
+			if plainText, err = keyring.DecryptWithAAD(encrypted, aad); err != nil {
+				return fmt.Errorf("%s: %w", field.Name, err)
+			}
+		}
+
+		if err := setPlainText(fieldValue, plainText); err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue)
+	return nil
+}
+
+// Value 필드 값을 암호화한 DB 값
+func (s *EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plainText, isNull, err := plainTextOf(fieldValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field.Name, err)
+	}
+	if isNull {
+		return nil, nil
+	}
+
+	if len(plainText) == 0 {
+		return "", nil
+	}
+
+	keyring, aad, err := s.prepare(ctx, field, dst)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := keyring.EncryptWithAAD(plainText, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field.Name, err)
+	}
+	return encrypted, nil
+}
+
+// prepare 키링과 aad 준비
+func (s *EncryptedSerializer) prepare(ctx context.Context, field *schema.Field, dst reflect.Value) (*crypto.Keyring, []byte, error) {
+	keyring, err := s.config.KeyProvider.Keyring(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("키링 조회 실패: %w", err)
+	}
+	if keyring == nil {
+		return nil, nil, errors.New("키링이 비어있습니다")
+	}
+
+	if !s.config.BindPrimaryKey {
+		return keyring, nil, nil
+	}
+
+	primaryField := field.Schema.PrioritizedPrimaryField
+	if primaryField == nil {
+		return nil, nil, fmt.Errorf("%s: 기본 키가 하나인 모델에서만 기본 키를 aad 로 묶을 수 있습니다", field.Schema.Name)
+	}
+	primaryKey, isZero := primaryField.ValueOf(ctx, dst)
+	if isZero {
+		return nil, nil, fmt.Errorf("%s: 기본 키 %s 가 비어있어 aad 를 만들 수 없습니다", field.Name, primaryField.Name)
+	}
+
+	// 포인터 기본 키 (ID *int64 등) 는 주소가 아닌 값으로 묶는다
+	primaryKey = reflect.Indirect(reflect.ValueOf(primaryKey)).Interface()
+	return keyring, crypto.NewAAD(field.Schema.Table, field.DBName, fmt.Sprint(primaryKey)), nil
+}
+
+// plainTextOf 필드 값의 평문, nil 포인터와 nil 슬라이스는 NULL
+//
+// string, []byte 를 바탕으로 한 이름 있는 타입 (type Password string 등) 도 받는다.
+func plainTextOf(fieldValue interface{}) (string, bool, error) {
+	if fieldValue == nil {
+		return "", true, nil
+	}
+	v := reflect.ValueOf(fieldValue)
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String(), false, nil
+	case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.String:
+		if v.IsNil() {
+			return "", true, nil
+		}
+		return v.Elem().String(), false, nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if v.IsNil() {
+			return "", true, nil
+		}
+		return string(v.Bytes()), false, nil
+	default:
+		return "", false, fmt.Errorf("암호화 serializer 는 string, *string, []byte 만 지원합니다: %T", fieldValue)
+	}
+}
+
+// setPlainText 필드 타입에 맞게 평문 설정
+func setPlainText(fieldValue reflect.Value, plainText string) error {
+	t := fieldValue.Type()
+	switch {
+	case t.Kind() == reflect.String:
+		fieldValue.SetString(plainText)
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.String:
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().SetString(plainText)
+		fieldValue.Set(ptr)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		fieldValue.SetBytes([]byte(plainText))
+	default:
+		return fmt.Errorf("암호화 serializer 는 string, *string, []byte 만 지원합니다: %s", t)
+	}
+	return nil
+}
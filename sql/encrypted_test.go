@@ -0,0 +1,206 @@
+package sql
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hsjahng/cmp-common/crypto"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+)
+
+type secretString string
+
+type encryptedAccount struct {
+	ID        string
+	SecretKey string       `gorm:"serializer:encrypted"`
+	Password  *string      `gorm:"serializer:encrypted"`
+	Token     []byte       `gorm:"serializer:encrypted"`
+	APIKey    secretString `gorm:"serializer:encrypted"`
+}
+
+func newTestKeyring(t *testing.T) *crypto.Keyring {
+	t.Helper()
+	keyring, err := crypto.NewKeyring("k1", crypto.CreateKeyFromString("serializer-test-key"))
+	require.NoError(t, err)
+	return keyring
+}
+
+// encryptLegacy 봉투 도입 이전 형식 base64(nonce||ciphertext) 로 암호화
+func encryptLegacy(t *testing.T, content string, key []byte) string {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(content), nil))
+}
+
+// roundTrip 필드 하나를 Value 로 암호화한 뒤 다른 행 값에 Scan
+func roundTrip(t *testing.T, s *EncryptedSerializer, fieldName string, src, dst *encryptedAccount) (interface{}, error) {
+	t.Helper()
+	accountSchema, err := schema.Parse(&encryptedAccount{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+	field := accountSchema.LookUpField(fieldName)
+	require.NotNil(t, field)
+
+	ctx := context.Background()
+	srcValue := reflect.ValueOf(src).Elem()
+	fieldValue := field.ReflectValueOf(ctx, srcValue).Interface()
+
+	dbValue, err := s.Value(ctx, field, srcValue, fieldValue)
+	if err != nil {
+		return nil, err
+	}
+	return dbValue, s.Scan(ctx, field, reflect.ValueOf(dst).Elem(), dbValue)
+}
+
+func TestEncryptedSerializer(t *testing.T) {
+	keyring := newTestKeyring(t)
+	config := DefaultEncryptedSerializerConfig()
+	config.KeyProvider = StaticKeyProvider(keyring)
+	require.NoError(t, RegisterEncryptedSerializer(config))
+	registered, ok := schema.GetSerializer(EncryptedSerializerName)
+	require.True(t, ok)
+	require.IsType(t, &EncryptedSerializer{}, registered)
+
+	s, err := NewEncryptedSerializer(config)
+	require.NoError(t, err)
+
+	t.Run("String", func(t *testing.T) {
+		src := &encryptedAccount{ID: "acc-1", SecretKey: "secret"}
+		dst := &encryptedAccount{ID: "acc-1"}
+		dbValue, err := roundTrip(t, s, "SecretKey", src, dst)
+		require.NoError(t, err)
+		require.True(t, crypto.IsEnvelope(dbValue.(string)))
+		require.Equal(t, "secret", dst.SecretKey)
+	})
+
+	t.Run("NamedString", func(t *testing.T) {
+		dst := &encryptedAccount{ID: "acc-1"}
+		dbValue, err := roundTrip(t, s, "APIKey", &encryptedAccount{ID: "acc-1", APIKey: "api-key"}, dst)
+		require.NoError(t, err)
+		require.True(t, crypto.IsEnvelope(dbValue.(string)))
+		require.Equal(t, secretString("api-key"), dst.APIKey)
+	})
+
+	t.Run("NullablePointer", func(t *testing.T) {
+		password := "p@ss"
+		dst := &encryptedAccount{ID: "acc-1"}
+		_, err := roundTrip(t, s, "Password", &encryptedAccount{ID: "acc-1", Password: &password}, dst)
+		require.NoError(t, err)
+		require.NotNil(t, dst.Password)
+		require.Equal(t, "p@ss", *dst.Password)
+
+		dst = &encryptedAccount{ID: "acc-1", Password: &password}
+		dbValue, err := roundTrip(t, s, "Password", &encryptedAccount{ID: "acc-1"}, dst)
+		require.NoError(t, err)
+		require.Nil(t, dbValue)
+		require.Nil(t, dst.Password)
+	})
+
+	t.Run("BytesAndEmpty", func(t *testing.T) {
+		dst := &encryptedAccount{ID: "acc-1"}
+		_, err := roundTrip(t, s, "Token", &encryptedAccount{ID: "acc-1", Token: []byte("token")}, dst)
+		require.NoError(t, err)
+		require.Equal(t, []byte("token"), dst.Token)
+
+		dst = &encryptedAccount{ID: "acc-1", SecretKey: "old"}
+		dbValue, err := roundTrip(t, s, "SecretKey", &encryptedAccount{ID: "acc-1"}, dst)
+		require.NoError(t, err)
+		require.Equal(t, "", dbValue)
+		require.Equal(t, "", dst.SecretKey)
+	})
+
+	t.Run("BoundToPrimaryKey", func(t *testing.T) {
+		// 다른 행으로 옮긴 암호문은 복호화되지 않음
+		_, err := roundTrip(t, s, "SecretKey", &encryptedAccount{ID: "acc-1", SecretKey: "secret"}, &encryptedAccount{ID: "acc-2"})
+		require.Error(t, err)
+
+		// 기본 키가 없으면 암호화 거부
+		_, err = roundTrip(t, s, "SecretKey", &encryptedAccount{SecretKey: "secret"}, &encryptedAccount{})
+		require.Error(t, err)
+	})
+
+	t.Run("PointerPrimaryKey", func(t *testing.T) {
+		type pointerKeyAccount struct {
+			ID        *int64
+			SecretKey string `gorm:"serializer:encrypted"`
+		}
+		accountSchema, err := schema.Parse(&pointerKeyAccount{}, &sync.Map{}, schema.NamingStrategy{})
+		require.NoError(t, err)
+		field := accountSchema.LookUpField("SecretKey")
+
+		// 주소가 다른 포인터라도 값이 같으면 같은 행
+		ctx := context.Background()
+		writeID, readID := int64(7), int64(7)
+		src := reflect.ValueOf(&pointerKeyAccount{ID: &writeID, SecretKey: "secret"}).Elem()
+		dbValue, err := s.Value(ctx, field, src, "secret")
+		require.NoError(t, err)
+
+		dst := &pointerKeyAccount{ID: &readID}
+		require.NoError(t, s.Scan(ctx, field, reflect.ValueOf(dst).Elem(), dbValue))
+		require.Equal(t, "secret", dst.SecretKey)
+
+		otherID := int64(8)
+		require.Error(t, s.Scan(ctx, field, reflect.ValueOf(&pointerKeyAccount{ID: &otherID}).Elem(), dbValue))
+	})
+
+	t.Run("LegacyNotBoundToPrimaryKey", func(t *testing.T) {
+		accountSchema, err := schema.Parse(&encryptedAccount{}, &sync.Map{}, schema.NamingStrategy{})
+		require.NoError(t, err)
+		field := accountSchema.LookUpField("SecretKey")
+		legacy := encryptLegacy(t, "secret", crypto.CreateKeyFromString("serializer-test-key"))
+
+		// 다른 행에서 복사해 온 기존 형식 값은 읽지 않는다
+		dst := &encryptedAccount{ID: "acc-2"}
+		err = s.Scan(context.Background(), field, reflect.ValueOf(dst).Elem(), legacy)
+		require.ErrorIs(t, err, crypto.ErrMalformedCiphertext)
+		require.Empty(t, dst.SecretKey)
+
+		// 옮기는 동안에는 AllowLegacy 로 허용
+		allowLegacy := config
+		allowLegacy.AllowLegacy = true
+		migrating, err := NewEncryptedSerializer(allowLegacy)
+		require.NoError(t, err)
+		require.NoError(t, migrating.Scan(context.Background(), field, reflect.ValueOf(dst).Elem(), legacy))
+		require.Equal(t, "secret", dst.SecretKey)
+	})
+
+	t.Run("WithoutPrimaryKeyBinding", func(t *testing.T) {
+		s, err := NewEncryptedSerializer(EncryptedSerializerConfig{KeyProvider: StaticKeyProvider(keyring)})
+		require.NoError(t, err)
+
+		dst := &encryptedAccount{ID: "acc-2"}
+		dbValue, err := roundTrip(t, s, "SecretKey", &encryptedAccount{SecretKey: "secret"}, dst)
+		require.NoError(t, err)
+		require.Equal(t, "secret", dst.SecretKey)
+
+		decrypted, err := keyring.Decrypt(dbValue.(string))
+		require.NoError(t, err)
+		require.Equal(t, "secret", decrypted)
+	})
+
+	t.Run("KeyProviderError", func(t *testing.T) {
+		_, err := NewEncryptedSerializer(EncryptedSerializerConfig{})
+		require.Error(t, err)
+
+		s, err := NewEncryptedSerializer(EncryptedSerializerConfig{
+			KeyProvider: KeyProviderFunc(func(context.Context) (*crypto.Keyring, error) {
+				return nil, context.Canceled
+			}),
+		})
+		require.NoError(t, err)
+		_, err = roundTrip(t, s, "SecretKey", &encryptedAccount{ID: "acc-1", SecretKey: "secret"}, &encryptedAccount{})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
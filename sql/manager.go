@@ -0,0 +1,180 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hsjahng/cmp-common/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// closePollInterval Close 에서 사용 중인 연결이 반환되었는지 확인하는 간격
+const closePollInterval = 10 * time.Millisecond
+
+// DBManager 이름 붙인 DB 연결("DB_COMMON", "DB_DEFAULT" 등)을 관리하고 종료할 때 한꺼번에 닫는다
+//
+//	manager := sql.NewDBManager()
+//	defer manager.Close(shutdownCtx)
+//	db, err := manager.Open(ctx, "DB_COMMON", config, sql.DefaultRetryConfig)
+//
+// 여러 고루틴에서 동시에 사용해도 안전하다.
+type DBManager struct {
+	mu     sync.RWMutex
+	dbs    map[string]*gorm.DB
+	closed bool
+}
+
+// NewDBManager 빈 DBManager 생성
+func NewDBManager() *DBManager {
+	return &DBManager{dbs: make(map[string]*gorm.DB)}
+}
+
+// Open ConnectWithRetry 로 접속해 name 으로 등록
+func (m *DBManager) Open(ctx context.Context, name string, config DBConfig, retryConfig RetryConfig) (*gorm.DB, error) {
+	if err := m.checkName(name); err != nil {
+		return nil, err
+	}
+
+	db, err := ConnectWithRetry(ctx, config, retryConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.Add(name, db); err != nil {
+		// 접속하는 동안 같은 이름으로 등록되었거나 Close 됨
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return nil, err
+	}
+	return db, nil
+}
+
+// Add 이미 연 db 를 name 으로 등록, 등록한 db 는 Close 에서 닫힌다
+func (m *DBManager) Add(name string, db *gorm.DB) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkNameLocked(name); err != nil {
+		return err
+	}
+	m.dbs[name] = db
+	return nil
+}
+
+func (m *DBManager) checkName(name string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.checkNameLocked(name)
+}
+
+func (m *DBManager) checkNameLocked(name string) error {
+	if m.closed {
+		return errors.New("DBManager가 이미 닫혔습니다")
+	}
+	if len(name) == 0 {
+		return errors.New("DB 이름은 비어있을 수 없습니다")
+	}
+	if _, ok := m.dbs[name]; ok {
+		return fmt.Errorf("이미 등록된 DB입니다: %s", name)
+	}
+	return nil
+}
+
+// Get name 으로 등록한 db
+func (m *DBManager) Get(name string) (*gorm.DB, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	db, ok := m.dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("등록되지 않은 DB입니다: %s", name)
+	}
+	return db, nil
+}
+
+// Names 등록한 이름 (정렬)
+func (m *DBManager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.dbs))
+	for name := range m.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats 등록한 db 의 커넥션 풀 상태 (이름순)
+func (m *DBManager) Stats() []PoolStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := make([]PoolStats, 0, len(m.dbs))
+	for name, db := range m.dbs {
+		if s, err := GetPoolStats(name, db); err == nil {
+			stats = append(stats, s)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Close 새 등록을 막고, 사용 중인 연결이 반환되기를 기다린 뒤 모든 db 를 닫는다
+//
+// ctx 가 먼저 끝나면 기다리지 않고 닫은 뒤 ctx 오류를 함께 반환한다. 두 번 이상 호출해도 된다.
+func (m *DBManager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	dbs := m.dbs
+	m.dbs = make(map[string]*gorm.DB)
+	m.closed = true
+	m.mu.Unlock()
+
+	log := logger.GetSugaredLogger()
+	if log == nil {
+		log = zap.NewNop().Sugar()
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for name, db := range dbs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := closeDB(ctx, db); err != nil {
+				log.Errorf("DB 종료 실패 %s: %v", name, err)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("DB 종료 실패 %s: %w", name, err))
+				mu.Unlock()
+				return
+			}
+			log.Infof("DB 종료: %s", name)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// closeDB 사용 중인 연결이 없어질 때까지 (또는 ctx 가 끝날 때까지) 기다렸다가 닫는다
+func closeDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	var waitErr error
+	ticker := time.NewTicker(closePollInterval)
+	defer ticker.Stop()
+	for waitErr == nil && sqlDB.Stats().InUse > 0 {
+		select {
+		case <-ctx.Done():
+			waitErr = ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return errors.Join(waitErr, sqlDB.Close())
+}
@@ -0,0 +1,105 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Register", func(t *testing.T) {
+		manager := NewDBManager()
+		common, commonMock := newMockDB(t)
+		def, defMock := newMockDB(t)
+		commonMock.ExpectClose()
+		defMock.ExpectClose()
+
+		require.NoError(t, manager.Add("DB_DEFAULT", def))
+		require.NoError(t, manager.Add("DB_COMMON", common))
+		require.Error(t, manager.Add("DB_COMMON", common))
+		require.Error(t, manager.Add("", common))
+		require.Equal(t, []string{"DB_COMMON", "DB_DEFAULT"}, manager.Names())
+
+		db, err := manager.Get("DB_COMMON")
+		require.NoError(t, err)
+		require.Same(t, common, db)
+		_, err = manager.Get("DB_UNKNOWN")
+		require.ErrorContains(t, err, "DB_UNKNOWN")
+
+		stats := manager.Stats()
+		require.Len(t, stats, 2)
+		require.Equal(t, "DB_COMMON", stats[0].Name)
+		require.Equal(t, "DB_DEFAULT", stats[1].Name)
+
+		require.NoError(t, manager.Close(ctx))
+		require.NoError(t, commonMock.ExpectationsWereMet())
+		require.NoError(t, defMock.ExpectationsWereMet())
+
+		// 닫은 뒤에는 등록할 수 없고, 다시 닫아도 된다
+		require.Empty(t, manager.Names())
+		require.ErrorContains(t, manager.Add("DB_COMMON", common), "닫혔습니다")
+		require.NoError(t, manager.Close(ctx))
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		mockDialector(t)
+		manager := NewDBManager()
+		retryConfig, _ := testRetryConfig(1)
+
+		config := testDBConfig()
+		config.Pool = PoolConfig{MaxOpenConns: 4}
+		db, err := manager.Open(ctx, "DB_COMMON", config, retryConfig)
+		require.NoError(t, err)
+		require.NotNil(t, db)
+		require.Equal(t, 4, manager.Stats()[0].MaxOpenConnections)
+
+		_, err = manager.Open(ctx, "DB_COMMON", config, retryConfig)
+		require.ErrorContains(t, err, "이미 등록된")
+		require.NoError(t, manager.Close(ctx))
+
+		_, err = manager.Open(ctx, "DB_DEFAULT", config, retryConfig)
+		require.Error(t, err)
+	})
+
+	t.Run("CloseWaitsInUse", func(t *testing.T) {
+		manager := NewDBManager()
+		db, mock := newMockDB(t)
+		mock.ExpectClose()
+		require.NoError(t, manager.Add("DB_COMMON", db))
+
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		conn, err := sqlDB.Conn(ctx)
+		require.NoError(t, err)
+
+		// 사용 중인 연결이 반환될 때까지 기다린다
+		released := time.Now().Add(30 * time.Millisecond)
+		time.AfterFunc(30*time.Millisecond, func() { _ = conn.Close() })
+		require.NoError(t, manager.Close(ctx))
+		require.False(t, time.Now().Before(released))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CloseTimeout", func(t *testing.T) {
+		manager := NewDBManager()
+		db, mock := newMockDB(t)
+		mock.ExpectClose()
+		require.NoError(t, manager.Add("DB_COMMON", db))
+
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		conn, err := sqlDB.Conn(ctx)
+		require.NoError(t, err)
+
+		// ctx 가 먼저 끝나면 기다리지 않고 닫는다
+		closeCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, manager.Close(closeCtx), context.DeadlineExceeded)
+		require.NoError(t, conn.Close())
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,102 @@
+package sql
+
+import (
+	gosql "database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PoolConfig 커넥션 풀 설정, 0 인 항목은 database/sql 기본값을 그대로 둔다
+type PoolConfig struct {
+	MaxOpenConns    int           `yaml:"maxOpenConns"`    // 최대 연결 수
+	MaxIdleConns    int           `yaml:"maxIdleConns"`    // 최대 유휴 연결 수
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"` // 연결을 재사용할 최대 시간 (예: 1h)
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"` // 유휴 연결을 유지할 최대 시간 (예: 30m)
+}
+
+// DefaultPoolConfig 기본 설정
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    20,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: time.Hour,
+		ConnMaxIdleTime: 30 * time.Minute,
+	}
+}
+
+// Apply db 의 커넥션 풀에 설정 적용
+func (p PoolConfig) Apply(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	p.apply(sqlDB)
+	return nil
+}
+
+func (p PoolConfig) apply(sqlDB *gosql.DB) {
+	if p.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// merge 0 이 아닌 값으로 덮어쓰기
+func (p *PoolConfig) merge(o PoolConfig) {
+	if o.MaxOpenConns != 0 {
+		p.MaxOpenConns = o.MaxOpenConns
+	}
+	if o.MaxIdleConns != 0 {
+		p.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.ConnMaxLifetime != 0 {
+		p.ConnMaxLifetime = o.ConnMaxLifetime
+	}
+	if o.ConnMaxIdleTime != 0 {
+		p.ConnMaxIdleTime = o.ConnMaxIdleTime
+	}
+}
+
+// PoolStats 모니터링용 커넥션 풀 상태
+type PoolStats struct {
+	Name               string        `json:"name"`
+	MaxOpenConnections int           `json:"maxOpenConnections"`
+	OpenConnections    int           `json:"openConnections"`
+	InUse              int           `json:"inUse"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"waitCount"`    // 연결을 기다린 횟수
+	WaitDuration       time.Duration `json:"waitDuration"` // 연결을 기다린 총 시간
+	MaxIdleClosed      int64         `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64         `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64         `json:"maxLifetimeClosed"`
+}
+
+// GetPoolStats db 의 커넥션 풀 상태
+func GetPoolStats(name string, db *gorm.DB) (PoolStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return PoolStats{}, err
+	}
+	stats := sqlDB.Stats()
+	return PoolStats{
+		Name:               name,
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}, nil
+}
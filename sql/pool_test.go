@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfig(t *testing.T) {
+	db, _ := newMockDB(t)
+
+	require.NoError(t, PoolConfig{MaxOpenConns: 7, MaxIdleConns: 3, ConnMaxLifetime: time.Minute}.Apply(db))
+	stats, err := GetPoolStats("test", db)
+	require.NoError(t, err)
+	require.Equal(t, "test", stats.Name)
+	require.Equal(t, 7, stats.MaxOpenConnections)
+
+	// 0 인 항목은 바꾸지 않는다
+	require.NoError(t, PoolConfig{MaxIdleConns: 1}.Apply(db))
+	stats, err = GetPoolStats("test", db)
+	require.NoError(t, err)
+	require.Equal(t, 7, stats.MaxOpenConnections)
+
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"maxOpenConnections":7`)
+}
+
+func TestLoadDBConfigPool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+host: localhost
+user: maestro
+database: test
+pool:
+  maxOpenConns: 50
+  connMaxLifetime: 2h
+`), 0600))
+	t.Setenv("TEST_POOL_MAX_IDLE_CONNS", "5")
+	t.Setenv("TEST_POOL_CONN_MAX_IDLE_TIME", "1m")
+
+	config, err := LoadDBConfig(DBConfigSources{File: path, EnvPrefix: "TEST_POOL"})
+	require.NoError(t, err)
+	require.Equal(t, PoolConfig{
+		MaxOpenConns:    50,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 2 * time.Hour,
+		ConnMaxIdleTime: time.Minute,
+	}, config.Pool)
+
+	t.Setenv("TEST_POOL_CONN_MAX_IDLE_TIME", "forever")
+	_, err = LoadDBConfig(DBConfigSources{File: path, EnvPrefix: "TEST_POOL"})
+	require.ErrorContains(t, err, "TEST_POOL_CONN_MAX_IDLE_TIME")
+}
@@ -0,0 +1,248 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/hsjahng/cmp-common/logger"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 재시도
+//
+// 실패하면 InitialWait 부터 Factor 배씩 늘린 시간(MaxWait 까지)에 ±Jitter 비율의 무작위 값을 더해 기다린 뒤
+// 다시 시도한다. 처음 시도를 포함해 최대 MaxRetries+1 번 시도하고, 재시도할 오류가 아니거나
+// context 가 취소되면 바로 반환한다. 접속(ConnectWithRetry)은 네트워크 오류처럼 일시적인 오류만,
+// 트랜잭션(WithRetryTx)은 IsRetryableTxError 인 오류만 재시도한다.
+
+// MySQL 오류 코드
+const (
+	mysqlErrDBAccessDenied   = 1044 // ER_DBACCESS_DENIED_ERROR
+	mysqlErrAccessDenied     = 1045 // ER_ACCESS_DENIED_ERROR
+	mysqlErrBadDB            = 1049 // ER_BAD_DB_ERROR (unknown database)
+	mysqlErrAccessDeniedNoPw = 1698 // ER_ACCESS_DENIED_NO_PASSWORD_ERROR
+	mysqlErrTooManyConns     = 1040 // ER_CON_COUNT_ERROR (too many connections)
+	mysqlErrLockWaitTimeout  = 1205 // ER_LOCK_WAIT_TIMEOUT
+	mysqlErrLockDeadlock     = 1213 // ER_LOCK_DEADLOCK
+)
+
+// PostgreSQL SQLSTATE
+const (
+	pgErrInvalidAuthorization = "28000" // invalid_authorization_specification
+	pgErrInvalidPassword      = "28P01" // invalid_password
+	pgErrInvalidCatalogName   = "3D000" // invalid_catalog_name (없는 DB)
+	pgErrTooManyConnections   = "53300" // too_many_connections
+	pgErrCannotConnectNow     = "57P03" // cannot_connect_now (시작/종료 중)
+	pgErrSerializationFailure = "40001" // serialization_failure
+	pgErrDeadlockDetected     = "40P01" // deadlock_detected
+)
+
+type RetryConfig struct {
+	MaxRetries  int           // 최대 재시도 횟수 (1 보다 작으면 DefaultRetryConfig 사용)
+	MaxInterval time.Duration // Deprecated: MaxWait 사용, MaxWait 가 0 이면 대신 사용
+	InitialWait time.Duration // 초기 대기시간
+	MaxWait     time.Duration // 최대 대기 시간 (재시도 한 번의 대기 시간 상한)
+	Factor      float64       // 백오프 승수
+	Jitter      float64       // 무작위성 추가 (0~1, 대기 시간에 대한 비율)
+
+	Logger *zap.SugaredLogger // nil 이면 logger.GetSugaredLogger()
+}
+
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries:  10,
+	InitialWait: 100 * time.Millisecond,
+	MaxWait:     10 * time.Second,
+	Factor:      2.0,
+	Jitter:      0.1, // 무작위성
+}
+
+// normalize 비어있거나 잘못된 값을 기본값으로 채운다
+func (c RetryConfig) normalize() RetryConfig {
+	if c.MaxRetries < 1 {
+		log := c.Logger
+		c = DefaultRetryConfig
+		c.Logger = log
+	}
+	if c.InitialWait <= 0 {
+		c.InitialWait = DefaultRetryConfig.InitialWait
+	}
+	if c.MaxWait <= 0 {
+		c.MaxWait = c.MaxInterval
+	}
+	if c.MaxWait <= 0 {
+		c.MaxWait = DefaultRetryConfig.MaxWait
+	}
+	if c.Factor < 1 {
+		c.Factor = DefaultRetryConfig.Factor
+	}
+	c.Jitter = min(max(c.Jitter, 0), 1)
+	return c
+}
+
+// logger 로그를 남길 logger, 초기화되지 않았으면 남기지 않는다
+func (c RetryConfig) logger() *zap.SugaredLogger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	if log := logger.GetSugaredLogger(); log != nil {
+		return log
+	}
+	return zap.NewNop().Sugar()
+}
+
+// backoff attempt 번째 실패 후 기다릴 시간 (attempt 는 1 부터), normalize 한 설정에서 호출
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	wait := float64(c.InitialWait)
+	for i := 1; i < attempt && wait < float64(c.MaxWait); i++ {
+		wait *= c.Factor
+	}
+	wait = min(wait, float64(c.MaxWait))
+
+	// 지터(랜덤성) 적용
+	jitter := (rand.Float64()*2 - 1) * c.Jitter * wait
+	return time.Duration(wait + jitter)
+}
+
+// retry fn 이 성공할 때까지 retryable 인 오류를 재시도, op 는 로그와 오류에 남길 작업 이름
+func retry(ctx context.Context, config RetryConfig, op string, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	config = config.normalize()
+	log := config.logger()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log.Infof("%s 성공 (%d번째 시도)", op, attempt)
+			}
+			return nil
+		}
+		if !retryable(err) {
+			if attempt > 1 {
+				log.Errorf("%s 실패 (%d번째 시도), 재시도하지 않는 오류: %v", op, attempt, err)
+			}
+			return err
+		}
+		if attempt > config.MaxRetries {
+			log.Errorf("%s 실패, 재시도 %d회 초과: %v", op, config.MaxRetries, err)
+			return fmt.Errorf("%s 실패 (%d회 시도): %w", op, attempt, err)
+		}
+
+		wait := config.backoff(attempt)
+		log.Warnf("%s 실패 (%d/%d번째 시도), %s 후 재시도: %v", op, attempt, config.MaxRetries+1, wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s 중단 (마지막 오류: %v): %w", op, err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryableConnError 접속 오류 중 재시도할 일시적인 오류
+//
+// 네트워크 타임아웃, 연결 거부/끊김, 네트워크·호스트 도달 불가, DNS 일시 실패와 아직 등록되지 않은 이름
+// (Pod, DB 가 시작되는 중), driver.ErrBadConn, MariaDB 1040, PostgreSQL 53300/57P03 만 재시도하고
+// DSN 파싱 실패, 알 수 없는 드라이버 같은 나머지 오류는 바로 반환한다.
+// deadlock, lock wait timeout 은 트랜잭션 오류이므로 IsRetryableTxError 에서만 다룬다.
+func isRetryableConnError(err error) bool {
+	if err == nil || IsNonRetryableError(err) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysqlDriver.ErrInvalidConn) {
+		return true
+	}
+	for _, errno := range []syscall.Errno{syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.ENETUNREACH, syscall.EHOSTUNREACH} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && (dnsErr.IsTemporary || dnsErr.IsNotFound) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var mysqlErr *mysqlDriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrTooManyConns:
+			return true
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgErrTooManyConnections, pgErrCannotConnectNow:
+			return true
+		}
+	}
+	return false
+}
+
+// IsNonRetryableError 다시 시도해도 결과가 같은 오류 (MariaDB, PostgreSQL 의 인증 실패, 없는 DB, context 취소 등)
+func IsNonRetryableError(err error) bool {
+	// 무시해도 될 오류들은 지나치도록 한다
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var mysqlErr *mysqlDriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrDBAccessDenied, mysqlErrAccessDenied, mysqlErrBadDB, mysqlErrAccessDeniedNoPw:
+			return true
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgErrInvalidAuthorization, pgErrInvalidPassword, pgErrInvalidCatalogName:
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryableTxError 트랜잭션을 처음부터 다시 실행하면 성공할 수 있는 오류
+// (MariaDB deadlock 1213, lock wait timeout 1205, PostgreSQL serialization_failure 40001, deadlock_detected 40P01)
+func IsRetryableTxError(err error) bool {
+	if IsNonRetryableError(err) {
+		return false
+	}
+
+	var mysqlErr *mysqlDriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrLockDeadlock, mysqlErrLockWaitTimeout:
+			return true
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgErrSerializationFailure, pgErrDeadlockDetected:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// testRetryConfig 짧게 기다리는 재시도 설정
+func testRetryConfig(maxRetries int) (RetryConfig, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return RetryConfig{
+		MaxRetries:  maxRetries,
+		InitialWait: time.Millisecond,
+		MaxWait:     5 * time.Millisecond,
+		Factor:      2,
+		Logger:      zap.New(core).Sugar(),
+	}, logs
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	config := RetryConfig{MaxRetries: 5, InitialWait: 100 * time.Millisecond, MaxWait: time.Second, Factor: 2}.normalize()
+	require.Equal(t, 100*time.Millisecond, config.backoff(1))
+	require.Equal(t, 200*time.Millisecond, config.backoff(2))
+	require.Equal(t, 800*time.Millisecond, config.backoff(4))
+	require.Equal(t, time.Second, config.backoff(5))
+	require.Equal(t, time.Second, config.backoff(1000))
+
+	config.Jitter = 0.1
+	for i := 0; i < 100; i++ {
+		wait := config.backoff(2)
+		require.GreaterOrEqual(t, wait, 180*time.Millisecond)
+		require.LessOrEqual(t, wait, 220*time.Millisecond)
+	}
+
+	// 비어있는 설정은 기본값, MaxWait 가 없으면 MaxInterval
+	require.Equal(t, DefaultRetryConfig, RetryConfig{}.normalize())
+	config = RetryConfig{MaxRetries: 1, MaxInterval: time.Minute, Factor: 0.5, Jitter: 3}.normalize()
+	require.Equal(t, time.Minute, config.MaxWait)
+	require.Equal(t, DefaultRetryConfig.InitialWait, config.InitialWait)
+	require.Equal(t, DefaultRetryConfig.Factor, config.Factor)
+	require.Equal(t, 1.0, config.Jitter)
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	transient := fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)
+
+	t.Run("Success", func(t *testing.T) {
+		config, logs := testRetryConfig(3)
+		attempts := 0
+		err := retry(ctx, config, "테스트", isRetryableConnError, func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return transient
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+		require.Equal(t, 2, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+		require.Equal(t, 1, logs.FilterMessageSnippet("3번째 시도").Len())
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		config, logs := testRetryConfig(2)
+		attempts := 0
+		err := retry(ctx, config, "테스트", isRetryableConnError, func(context.Context) error {
+			attempts++
+			return transient
+		})
+		require.ErrorIs(t, err, transient)
+		require.Equal(t, 3, attempts)
+		require.Equal(t, 1, logs.FilterLevelExact(zapcore.ErrorLevel).Len())
+	})
+
+	t.Run("NonRetryable", func(t *testing.T) {
+		config, _ := testRetryConfig(5)
+		denied := &mysqlDriver.MySQLError{Number: 1045, Message: "Access denied for user"}
+		attempts := 0
+		err := retry(ctx, config, "테스트", isRetryableConnError, func(context.Context) error {
+			attempts++
+			return fmt.Errorf("접속 실패: %w", denied)
+		})
+		require.ErrorIs(t, err, denied)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("MalformedDSN", func(t *testing.T) {
+		config, _ := testRetryConfig(5)
+		attempts := 0
+		err := retry(ctx, config, "테스트", isRetryableConnError, func(context.Context) error {
+			attempts++
+			_, err := gorm.Open(mysql.Open("user:pw@tcp(127.0.0.1:3306"), newGormConfig(gormLogger.Silent, true))
+			return err
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		config, _ := testRetryConfig(5)
+		config.InitialWait = time.Hour
+		config.MaxWait = time.Hour
+
+		ctx, cancel := context.WithCancel(ctx)
+		attempts := 0
+		err := retry(ctx, config, "테스트", isRetryableConnError, func(context.Context) error {
+			attempts++
+			cancel()
+			return transient
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorContains(t, err, transient.Error())
+		require.Equal(t, 1, attempts)
+	})
+}
+
+func TestIsRetryableConnError(t *testing.T) {
+	for _, err := range []error{
+		driver.ErrBadConn,
+		mysqlDriver.ErrInvalidConn,
+		fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED),
+		&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+		&net.DNSError{IsTimeout: true},
+		&net.DNSError{Name: "mariadb.db.svc", IsTemporary: true},
+		&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Name: "mariadb.db.svc", IsNotFound: true}},
+		&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ENETUNREACH},
+		fmt.Errorf("dial tcp: %w", syscall.EHOSTUNREACH),
+		&mysqlDriver.MySQLError{Number: 1040, Message: "Too many connections"},
+		&pgconn.PgError{Code: "53300", Message: "too many connections"},
+	} {
+		require.True(t, isRetryableConnError(err), "%v", err)
+	}
+
+	for _, err := range []error{
+		errors.New("invalid DSN: missing the slash separating the database name"),
+		errors.New(`sql: unknown driver "nope"`),
+		&mysqlDriver.MySQLError{Number: 1045},
+		// 트랜잭션 오류는 접속 재시도 대상이 아니다
+		&mysqlDriver.MySQLError{Number: 1205},
+		&mysqlDriver.MySQLError{Number: 1213},
+		context.Canceled,
+		nil,
+	} {
+		require.False(t, isRetryableConnError(err), "%v", err)
+	}
+}
+
+func TestIsNonRetryableError(t *testing.T) {
+	for _, err := range []error{
+		gorm.ErrRecordNotFound,
+		context.Canceled,
+		fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+		&mysqlDriver.MySQLError{Number: 1044},
+		&mysqlDriver.MySQLError{Number: 1045},
+		fmt.Errorf("wrapped: %w", &mysqlDriver.MySQLError{Number: 1049, Message: "Unknown database"}),
+		&mysqlDriver.MySQLError{Number: 1698},
+		&pgconn.PgError{Code: "28P01", Message: "password authentication failed"},
+		fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "3D000", Message: "database does not exist"}),
+	} {
+		require.True(t, IsNonRetryableError(err), "%v", err)
+	}
+
+	for _, err := range []error{
+		errors.New("dial tcp: connection refused"),
+		mysqlDriver.ErrInvalidConn,
+		&mysqlDriver.MySQLError{Number: 1040, Message: "Too many connections"},
+		&pgconn.PgError{Code: "53300", Message: "too many connections"},
+		nil,
+	} {
+		require.False(t, IsNonRetryableError(err), "%v", err)
+	}
+}
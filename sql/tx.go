@@ -0,0 +1,23 @@
+package sql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// WithRetryTx fn 을 트랜잭션으로 실행하고 IsRetryableTxError(deadlock, lock wait timeout 등) 이면 처음부터 다시 실행
+//
+// 재시도하면 fn 이 여러 번 호출되므로 fn 은 트랜잭션 밖의 상태를 바꾸지 않아야 한다.
+// fn 이 반환한 다른 오류는 롤백 후 그대로 반환한다. db 가 이미 트랜잭션 안이면 바깥 트랜잭션이
+// 함께 롤백되므로 재시도하지 않고 한 번만 실행한다.
+func WithRetryTx(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error, config RetryConfig) error {
+	db = db.WithContext(ctx)
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return db.Transaction(fn)
+	}
+
+	return retry(ctx, config, "트랜잭션", IsRetryableTxError, func(context.Context) error {
+		return db.Transaction(fn)
+	})
+}
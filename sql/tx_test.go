@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	require.NoError(t, err)
+	return db, mock
+}
+
+func updateStock(tx *gorm.DB) error {
+	return tx.Exec("UPDATE stock SET count = count - 1 WHERE id = ?", 1).Error
+}
+
+func TestWithRetryTx(t *testing.T) {
+	ctx := context.Background()
+	deadlock := &mysqlDriver.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	lockWait := &mysqlDriver.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+
+	t.Run("Retry", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE stock").WillReturnError(deadlock)
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE stock").WillReturnError(lockWait)
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE stock").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		config, _ := testRetryConfig(3)
+		attempts := 0
+		err := WithRetryTx(ctx, db, func(tx *gorm.DB) error {
+			attempts++
+			return updateStock(tx)
+		}, config)
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		for i := 0; i < 2; i++ {
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE stock").WillReturnError(deadlock)
+			mock.ExpectRollback()
+		}
+
+		config, _ := testRetryConfig(1)
+		err := WithRetryTx(ctx, db, updateStock, config)
+		require.ErrorIs(t, err, deadlock)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NotRetried", func(t *testing.T) {
+		for _, fnErr := range []error{
+			errors.New("잔고 부족"),
+			gorm.ErrRecordNotFound,
+			&mysqlDriver.MySQLError{Number: 1062, Message: "Duplicate entry"},
+		} {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+
+			config, _ := testRetryConfig(3)
+			attempts := 0
+			err := WithRetryTx(ctx, db, func(tx *gorm.DB) error {
+				attempts++
+				return fnErr
+			}, config)
+			require.ErrorIs(t, err, fnErr)
+			require.Equal(t, 1, attempts)
+			require.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE stock").WillReturnError(deadlock)
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		// 바깥 트랜잭션 안에서는 재시도하지 않는다
+		config, _ := testRetryConfig(3)
+		attempts := 0
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return WithRetryTx(ctx, tx, func(tx *gorm.DB) error {
+				attempts++
+				return updateStock(tx)
+			}, config)
+		})
+		require.ErrorIs(t, err, deadlock)
+		require.Equal(t, 1, attempts)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	require.True(t, IsRetryableTxError(&mysqlDriver.MySQLError{Number: 1213}))
+	require.True(t, IsRetryableTxError(errors.Join(errors.New("update"), &mysqlDriver.MySQLError{Number: 1205})))
+	require.True(t, IsRetryableTxError(&pgconn.PgError{Code: "40001"}))
+	require.True(t, IsRetryableTxError(&pgconn.PgError{Code: "40P01"}))
+	require.False(t, IsRetryableTxError(&pgconn.PgError{Code: "23505"}))
+	require.False(t, IsRetryableTxError(&mysqlDriver.MySQLError{Number: 1062}))
+	require.False(t, IsRetryableTxError(&mysqlDriver.MySQLError{Number: 1045}))
+	require.False(t, IsRetryableTxError(context.Canceled))
+	require.False(t, IsRetryableTxError(errors.New("connection refused")))
+	require.False(t, IsRetryableTxError(nil))
+}